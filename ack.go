@@ -0,0 +1,97 @@
+package ringbuf
+
+// NewAckBuf returns an AckBuf decorating buf.
+func NewAckBuf[F any](buf Buffer[F]) *AckBuf[F] {
+	return &AckBuf[F]{
+		Buffer: buf,
+		acked:  ackSet{},
+	}
+}
+
+// AckBuf is a Buffer decorator for retransmission buffers: items are
+// acknowledged individually, in any order, and dropped as soon as every item
+// before them is acknowledged too.
+type AckBuf[F any] struct {
+	Buffer[F]
+	acked ackSet
+}
+
+// Ack marks the item at pos as acknowledged and drops the longest prefix of
+// acknowledged items.
+func (b *AckBuf[F]) Ack(pos Position) error {
+	first, next := b.FirstPosition(), b.NextPosition()
+	if pos-first < 0 || 0 <= pos-next {
+		return errOutOfRange(pos, first, next)
+	}
+	b.acked[pos] = struct{}{}
+	if cur := b.acked.advance(first); cur != first {
+		return b.Buffer.Drop(cur - 1)
+	}
+	return nil
+}
+
+// Acked reports whether the item at pos is retained and acknowledged.
+func (b *AckBuf[F]) Acked(pos Position) bool {
+	_, ok := b.acked[pos]
+	return ok
+}
+
+// Drop drops up to drop and then the acknowledged items that follow.
+func (b *AckBuf[F]) Drop(drop Position) error {
+	if err := b.Buffer.Drop(drop); err != nil {
+		return err
+	}
+	first := b.FirstPosition()
+	for pos := range b.acked {
+		if pos-first < 0 {
+			delete(b.acked, pos)
+		}
+	}
+	if cur := b.acked.advance(first); cur != first {
+		return b.Buffer.Drop(cur - 1)
+	}
+	return nil
+}
+
+func (b *AckBuf[F]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *AckBuf[F]) overwrites() bool {
+	return overwrites(b.Buffer)
+}
+
+func (b *AckBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *AckBuf[F]) ResetAt(start Position) {
+	b.Buffer.ResetAt(start)
+	b.acked = ackSet{}
+}
+
+func (b *AckBuf[F]) Clone() Buffer[F] {
+	acked := make(ackSet, len(b.acked))
+	for pos := range b.acked {
+		acked[pos] = struct{}{}
+	}
+	return &AckBuf[F]{
+		Buffer: b.Buffer.Clone(),
+		acked:  acked,
+	}
+}
+
+// ackSet is a set of acknowledged positions.
+type ackSet map[Position]struct{}
+
+// advance removes the positions acknowledged without a gap from cur and
+// returns the first position not acknowledged.
+func (s ackSet) advance(cur Position) Position {
+	for {
+		if _, ok := s[cur]; !ok {
+			return cur
+		}
+		delete(s, cur)
+		cur++
+	}
+}
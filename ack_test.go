@@ -0,0 +1,38 @@
+package ringbuf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAckBuf(t *testing.T) {
+	buf := NewAckBuf[int](NewRingBuf[int](4))
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Ack(2))
+	assert.NoError(t, buf.Ack(1))
+	assert.True(t, buf.Acked(1))
+	assert.Equal(t, Position(0), buf.FirstPosition())
+
+	assert.NoError(t, buf.Ack(0))
+	assert.Equal(t, Position(3), buf.FirstPosition())
+	assert.False(t, buf.Acked(1))
+	assert.True(t, errors.Is(buf.Ack(2), ErrOutOfRange))
+	assert.True(t, errors.Is(buf.Ack(4), ErrOutOfRange))
+
+	assert.NoError(t, buf.Append(4))
+	assert.NoError(t, buf.Ack(4))
+	clone := buf.Clone().(*AckBuf[int])
+	assert.True(t, clone.Acked(4))
+	assert.NoError(t, buf.Drop(3))
+	assert.Equal(t, Position(5), buf.FirstPosition())
+	assert.False(t, buf.Acked(4))
+
+	assert.NoError(t, clone.Ack(3))
+	assert.Equal(t, 0, clone.Len())
+	buf.ResetAt(10)
+	assert.False(t, buf.Acked(4))
+}
@@ -0,0 +1,165 @@
+package ringbuf
+
+// Number is the constraint of the values aggregated by AggregateBuf.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// NewAggregateBuf returns an AggregateBuf decorating buf, aggregating the
+// value of each item.
+func NewAggregateBuf[F any, A Number](buf Buffer[F], value func(item F) A) *AggregateBuf[F, A] {
+	b := &AggregateBuf[F, A]{
+		Buffer: buf,
+		value:  value,
+	}
+	b.load()
+	return b
+}
+
+// AggregateBuf is a Buffer decorator maintaining the count, sum, min and max
+// of the values of the retained items in O(1) amortized time per item. Min
+// and max are kept in monotonic queues.
+type AggregateBuf[F any, A Number] struct {
+	Buffer[F]
+	value  func(item F) A
+	start  Position // position of values[0]
+	values []A
+	sum    A
+	min    []aggregate[A] // increasing values
+	max    []aggregate[A] // decreasing values
+}
+
+type aggregate[A Number] struct {
+	pos   Position
+	value A
+}
+
+func (b *AggregateBuf[F, A]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *AggregateBuf[F, A]) overwrites() bool {
+	return overwrites(b.Buffer)
+}
+
+func (b *AggregateBuf[F, A]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *AggregateBuf[F, A]) AppendPos(item F) (Position, error) {
+	pos, err := b.Buffer.AppendPos(item)
+	if err != nil {
+		return pos, err
+	}
+	b.push(pos, b.value(item))
+	b.trim() // an overwriting append may have dropped items
+	return pos, nil
+}
+
+func (b *AggregateBuf[F, A]) Drop(drop Position) error {
+	if err := b.Buffer.Drop(drop); err != nil {
+		return err
+	}
+	b.trim()
+	return nil
+}
+
+func (b *AggregateBuf[F, A]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *AggregateBuf[F, A]) ResetAt(start Position) {
+	b.Buffer.ResetAt(start)
+	b.load()
+}
+
+func (b *AggregateBuf[F, A]) Clone() Buffer[F] {
+	return NewAggregateBuf[F, A](b.Buffer.Clone(), b.value)
+}
+
+// Count returns the number of aggregated items.
+func (b *AggregateBuf[F, A]) Count() int {
+	return len(b.values)
+}
+
+func (b *AggregateBuf[F, A]) Sum() A {
+	return b.sum
+}
+
+// Min returns the lowest value, or false if there is no item.
+func (b *AggregateBuf[F, A]) Min() (A, bool) {
+	if len(b.min) == 0 {
+		var zero A
+		return zero, false
+	}
+	return b.min[0].value, true
+}
+
+// Max returns the highest value, or false if there is no item.
+func (b *AggregateBuf[F, A]) Max() (A, bool) {
+	if len(b.max) == 0 {
+		var zero A
+		return zero, false
+	}
+	return b.max[0].value, true
+}
+
+// load aggregates the retained items from scratch.
+func (b *AggregateBuf[F, A]) load() {
+	var zero A
+	b.start = b.FirstPosition()
+	b.values = nil
+	b.sum = zero
+	b.min = nil
+	b.max = nil
+	items, err := b.ToSlice(b.start)
+	if err != nil {
+		return
+	}
+	for i, item := range items {
+		b.push(b.start+Position(i), b.value(item))
+	}
+}
+
+func (b *AggregateBuf[F, A]) push(pos Position, v A) {
+	b.values = append(b.values, v)
+	b.sum += v
+	for n := len(b.min); 0 < n && v <= b.min[n-1].value; n-- {
+		b.min = b.min[:n-1]
+	}
+	b.min = append(b.min, aggregate[A]{pos: pos, value: v})
+	for n := len(b.max); 0 < n && b.max[n-1].value <= v; n-- {
+		b.max = b.max[:n-1]
+	}
+	b.max = append(b.max, aggregate[A]{pos: pos, value: v})
+}
+
+// trim removes the values of the items dropped from the buffer.
+func (b *AggregateBuf[F, A]) trim() {
+	first := b.FirstPosition()
+	n := int(first - b.start)
+	if n < 0 { // Drop moved back to items not aggregated
+		b.load()
+		return
+	}
+	if n == 0 {
+		return
+	}
+	if len(b.values) < n {
+		n = len(b.values)
+	}
+	for _, v := range b.values[:n] {
+		b.sum -= v
+	}
+	b.values = b.values[n:]
+	b.start = first
+	for 0 < len(b.min) && b.min[0].pos-first < 0 {
+		b.min = b.min[1:]
+	}
+	for 0 < len(b.max) && b.max[0].pos-first < 0 {
+		b.max = b.max[1:]
+	}
+}
@@ -0,0 +1,67 @@
+package ringbuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateBuf(t *testing.T) {
+	buf := NewAggregateBuf[int, int](NewRingBuf[int](4), func(item int) int { return item })
+	_, ok := buf.Min()
+	assert.False(t, ok)
+	for _, item := range []int{3, 1, 4, 1} {
+		assert.NoError(t, buf.Append(item))
+	}
+	checkAggregates(t, buf, 4, 9, 1, 4)
+
+	assert.NoError(t, buf.Drop(1))
+	checkAggregates(t, buf, 2, 5, 1, 4)
+	assert.NoError(t, buf.Append(5))
+	assert.NoError(t, buf.Drop(2))
+	checkAggregates(t, buf, 2, 6, 1, 5)
+
+	clone := buf.Clone().(*AggregateBuf[int, int])
+	buf.ResetAt(10)
+	assert.Equal(t, 0, buf.Count())
+	assert.Equal(t, 0, buf.Sum())
+	checkAggregates(t, clone, 2, 6, 1, 5)
+}
+
+func TestAggregateBufOverwrite(t *testing.T) {
+	type sample struct{ v float64 }
+	buf := NewAggregateBuf[sample, float64](NewRingBuf[sample](3, WithOverwrite[sample]()),
+		func(item sample) float64 { return item.v })
+	for _, v := range []float64{2, 8, 4, 6, 1} {
+		assert.NoError(t, buf.Append(sample{v}))
+	}
+	assert.Equal(t, 3, buf.Count())
+	assert.Equal(t, 11.0, buf.Sum())
+	low, _ := buf.Min()
+	high, _ := buf.Max()
+	assert.Equal(t, 1.0, low)
+	assert.Equal(t, 6.0, high)
+}
+
+func checkAggregates(t *testing.T, buf *AggregateBuf[int, int], count, sum, low, high int) {
+	t.Helper()
+	assert.Equal(t, count, buf.Count())
+	assert.Equal(t, sum, buf.Sum())
+	v, ok := buf.Min()
+	assert.True(t, ok)
+	assert.Equal(t, low, v)
+	v, ok = buf.Max()
+	assert.True(t, ok)
+	assert.Equal(t, high, v)
+}
+
+func TestAggregateBufDropBack(t *testing.T) {
+	buf := NewAggregateBuf[int, int](NewRingBuf[int](4), func(item int) int { return item })
+	for _, item := range []int{3, 1, 4} {
+		assert.NoError(t, buf.Append(item))
+	}
+	assert.NoError(t, buf.Drop(1))
+	checkAggregates(t, buf, 1, 4, 4, 4)
+	assert.ErrorIs(t, buf.Drop(-1), ErrDropRegression)
+	checkAggregates(t, buf, 1, 4, 4, 4)
+}
@@ -1,6 +1,9 @@
 package ringbuf
 
 import (
+	"runtime"
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -15,7 +18,13 @@ func BenchmarkBufAppend(b *testing.B) {
 		buf  func() Buffer[int]
 	}{
 		{name: "ring", buf: func() Buffer[int] { return NewRingBuf[int](size) }},
+		{name: "ring-pow2", buf: func() Buffer[int] { return NewRingBufPow2[int](size) }},
 		{name: "slice", buf: func() Buffer[int] { return NewSliceBuf[int](size) }},
+		{name: "spsc", buf: func() Buffer[int] { return NewSPSCBuf[int](size) }},
+		{name: "mpmc", buf: func() Buffer[int] { return NewMPMCBuf[int](size) }},
+		{name: "sharded", buf: func() Buffer[int] { return NewShardedBuf[int](4, size/4) }},
+		{name: "snapshot", buf: func() Buffer[int] { return NewSnapshotBuf[int](size) }},
+		{name: "segmented", buf: func() Buffer[int] { return NewSegmentedBuf[int](size, 1024) }},
 		{name: "ring-sync", buf: func() Buffer[int] { return NewSyncBuf[int](NewRingBuf[int](size)) }},
 		{name: "slice-sync", buf: func() Buffer[int] { return NewSyncBuf[int](NewSliceBuf[int](size)) }},
 	}
@@ -36,6 +45,21 @@ func BenchmarkBufAppend(b *testing.B) {
 	}
 }
 
+func BenchmarkRingBufAppendAll(b *testing.B) {
+	batch := make([]int, skip*4)
+	buf := NewRingBuf[int](size)
+	for i := 0; i < b.N; i++ {
+		if buf.Free() < len(batch) {
+			if err := buf.Drop(buf.base + Position(buf.next) - 1); err != nil {
+				panic(err)
+			}
+		}
+		if _, err := buf.AppendAll(batch); err != nil {
+			panic(err)
+		}
+	}
+}
+
 func BenchmarkBufToSlice(b *testing.B) {
 	cases := []struct {
 		name string
@@ -43,6 +67,11 @@ func BenchmarkBufToSlice(b *testing.B) {
 	}{
 		{name: "ring", buf: func() Buffer[int] { return NewRingBuf[int](size) }},
 		{name: "slice", buf: func() Buffer[int] { return NewSliceBuf[int](size) }},
+		{name: "spsc", buf: func() Buffer[int] { return NewSPSCBuf[int](size) }},
+		{name: "mpmc", buf: func() Buffer[int] { return NewMPMCBuf[int](size) }},
+		{name: "sharded", buf: func() Buffer[int] { return NewShardedBuf[int](4, size/4) }},
+		{name: "snapshot", buf: func() Buffer[int] { return NewSnapshotBuf[int](size) }},
+		{name: "segmented", buf: func() Buffer[int] { return NewSegmentedBuf[int](size, 1024) }},
 		{name: "ring-sync", buf: func() Buffer[int] { return NewSyncBuf[int](NewRingBuf[int](size)) }},
 		{name: "slice-sync", buf: func() Buffer[int] { return NewSyncBuf[int](NewSliceBuf[int](size)) }},
 	}
@@ -78,6 +107,11 @@ func BenchmarkBufIterator(b *testing.B) {
 	}{
 		{name: "ring", buf: func() Buffer[int] { return NewRingBuf[int](size) }},
 		{name: "slice", buf: func() Buffer[int] { return NewSliceBuf[int](size) }},
+		{name: "spsc", buf: func() Buffer[int] { return NewSPSCBuf[int](size) }},
+		{name: "mpmc", buf: func() Buffer[int] { return NewMPMCBuf[int](size) }},
+		{name: "sharded", buf: func() Buffer[int] { return NewShardedBuf[int](4, size/4) }},
+		{name: "snapshot", buf: func() Buffer[int] { return NewSnapshotBuf[int](size) }},
+		{name: "segmented", buf: func() Buffer[int] { return NewSegmentedBuf[int](size, 1024) }},
 		{name: "ring-sync", buf: func() Buffer[int] { return NewSyncBuf[int](NewRingBuf[int](size)) }},
 		{name: "slice-sync", buf: func() Buffer[int] { return NewSyncBuf[int](NewSliceBuf[int](size)) }},
 	}
@@ -106,51 +140,219 @@ func BenchmarkBufIterator(b *testing.B) {
 	}
 }
 
-func NewSliceBuf[F any](size int) *SliceBuf[F] {
-	return &SliceBuf[F]{
-		size: size,
-		buf:  nil,
-		base: 0,
+func BenchmarkBufCopyTo(b *testing.B) {
+	cases := []struct {
+		name string
+		buf  func() Buffer[int]
+	}{
+		{name: "ring", buf: func() Buffer[int] { return NewRingBuf[int](size) }},
+		{name: "slice", buf: func() Buffer[int] { return NewSliceBuf[int](size) }},
+		{name: "spsc", buf: func() Buffer[int] { return NewSPSCBuf[int](size) }},
+		{name: "mpmc", buf: func() Buffer[int] { return NewMPMCBuf[int](size) }},
+		{name: "sharded", buf: func() Buffer[int] { return NewShardedBuf[int](4, size/4) }},
+		{name: "snapshot", buf: func() Buffer[int] { return NewSnapshotBuf[int](size) }},
+		{name: "segmented", buf: func() Buffer[int] { return NewSegmentedBuf[int](size, 1024) }},
+		{name: "ring-sync", buf: func() Buffer[int] { return NewSyncBuf[int](NewRingBuf[int](size)) }},
+		{name: "slice-sync", buf: func() Buffer[int] { return NewSyncBuf[int](NewSliceBuf[int](size)) }},
+	}
+	max := (size * 3) / 2
+	start := max - size
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			buf := c.buf()
+			for i := 0; i < max; i++ {
+				if i >= size {
+					if err := buf.Drop(Position(i - size)); err != nil {
+						panic(err)
+					}
+				}
+				if err := buf.Append(i); err != nil {
+					panic(err)
+				}
+			}
+			dst := make([]int, size)
+			for i := 0; i < b.N; i++ {
+				_, err := buf.CopyTo(dst, Position(start))
+				if err != nil {
+					panic(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSyncBufToSlicePooled(b *testing.B) {
+	buf := NewSyncBuf[int](NewRingBuf[int](size))
+	for i := 0; i < size; i++ {
+		if err := buf.Append(i); err != nil {
+			panic(err)
+		}
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		pooled, err := buf.ToSlicePooled(0)
+		if err != nil {
+			panic(err)
+		}
+		pooled.Release()
 	}
 }
 
-type SliceBuf[F any] struct {
-	size int
-	buf  []F
-	base Position
+// BenchmarkPaddedCounters compares a producer and a consumer updating
+// adjacent counters with counters padded to separate cache lines.
+func BenchmarkPaddedCounters(b *testing.B) {
+	b.Run("adjacent", func(b *testing.B) {
+		var counters struct{ head, tail uint64 }
+		benchmarkCounters(b, &counters.head, &counters.tail)
+	})
+	b.Run("padded", func(b *testing.B) {
+		var counters struct{ head, tail paddedCounter }
+		benchmarkCounters(b, &counters.head.v, &counters.tail.v)
+	})
 }
 
-func (b *SliceBuf[F]) Drop(drop Position) error {
-	base := b.base
-	if len(b.buf) <= int(drop-base) { // base + len(buf) <= drop
-		return ErrOutOfRange
+func benchmarkCounters(b *testing.B, head, tail *uint64) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for _, c := range []*uint64{head, tail} {
+		go func(c *uint64) {
+			defer wg.Done()
+			for i := 0; i < b.N; i++ {
+				atomic.AddUint64(c, 1)
+			}
+		}(c)
 	}
-	if 0 <= drop-base { // base <= drop
-		b.buf = b.buf[drop-base+1:]
-		b.base = drop + 1
+	wg.Wait()
+}
+
+// BenchmarkProducerConsumer passes items from a producer goroutine to a
+// consumer goroutine dropping them.
+func BenchmarkProducerConsumer(b *testing.B) {
+	cases := []struct {
+		name string
+		buf  func() Buffer[int]
+	}{
+		{name: "spsc", buf: func() Buffer[int] { return NewSPSCBuf[int](1024) }},
+		{name: "mpmc", buf: func() Buffer[int] { return NewMPMCBuf[int](1024) }},
+		{name: "ring-sync", buf: func() Buffer[int] { return NewSyncBuf[int](NewRingBuf[int](1024)) }},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			buf := c.buf()
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for pos := Position(0); pos < Position(b.N); {
+					if pos-buf.NextPosition() < 0 {
+						if err := buf.Drop(pos); err != nil {
+							panic(err)
+						}
+						pos++
+					} else {
+						runtime.Gosched()
+					}
+				}
+			}()
+			for i := 0; i < b.N; {
+				if buf.Append(i) == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+			<-done
+		})
 	}
-	return nil
 }
 
-func (b *SliceBuf[F]) Append(item F) error {
-	if b.size <= len(b.buf) {
-		return ErrBufferOverflow
+func BenchmarkMPMCBufClaim(b *testing.B) {
+	buf := NewMPMCBuf[int](size)
+	for i := 0; i < b.N; {
+		pos, slots, err := buf.Claim(skip * 4)
+		if err != nil {
+			if err := buf.Drop(buf.NextPosition() - 1); err != nil {
+				panic(err)
+			}
+			continue
+		}
+		for k := range slots {
+			slots[k] = i + k
+		}
+		if err := buf.Publish(pos, len(slots)); err != nil {
+			panic(err)
+		}
+		i += len(slots)
+	}
+}
+
+func BenchmarkRingBufToSlice(b *testing.B) {
+	cases := []struct {
+		name string
+		drop int
+	}{
+		{name: "contiguous", drop: 0},
+		{name: "wrapped", drop: size / 2},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			buf := NewRingBuf[int](size)
+			for i := 0; i < size+c.drop; i++ {
+				if i >= size {
+					if err := buf.Drop(Position(i - size)); err != nil {
+						panic(err)
+					}
+				}
+				if err := buf.Append(i); err != nil {
+					panic(err)
+				}
+			}
+			start := Position(c.drop)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := buf.ToSlice(start); err != nil {
+					panic(err)
+				}
+			}
+		})
 	}
-	b.buf = append(b.buf, item)
-	return nil
 }
 
-func (b *SliceBuf[F]) Iterator(start Position) (*Iterator[F], error) {
-	ss, err := b.ToSlice(start)
-	if err != nil {
-		return nil, err
+func BenchmarkRingBufGet(b *testing.B) {
+	cases := []struct {
+		name string
+		buf  *RingBuf[int]
+	}{
+		{name: "ring", buf: NewRingBuf[int](size)},
+		{name: "ring-pow2", buf: NewRingBufPow2[int](size)},
+	}
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			buf := c.buf
+			for i := 0; i < size+size/2; i++ {
+				if buf.IsFull() {
+					if err := buf.Drop(buf.FirstPosition()); err != nil {
+						panic(err)
+					}
+				}
+				if err := buf.Append(i); err != nil {
+					panic(err)
+				}
+			}
+			first := buf.FirstPosition()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := buf.Get(first + Position(i%size)); err != nil {
+					panic(err)
+				}
+			}
+		})
 	}
-	return NewIterator[F](ss), nil
 }
 
-func (b *SliceBuf[F]) ToSlice(start Position) ([]F, error) {
-	if start-b.base < 0 || len(b.buf) < int(start-b.base) {
-		return nil, ErrOutOfRange
+func BenchmarkRingBufOutOfRange(b *testing.B) {
+	buf := NewRingBuf[int](1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = buf.ToSlice(2048)
 	}
-	return b.buf[start-b.base:], nil
 }
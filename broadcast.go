@@ -0,0 +1,214 @@
+package ringbuf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// LagPolicy tells what happens to a Subscriber that falls behind by more than
+// the size of a Broadcaster.
+type LagPolicy int
+
+const (
+	// LagBlock makes Publish wait until the subscriber has read the oldest
+	// item.
+	LagBlock LagPolicy = iota
+	// LagSkip makes the subscriber skip the overwritten items, reporting them
+	// to its onGap.
+	LagSkip
+	// LagDisconnect makes Next of the subscriber fail with
+	// ErrSlowSubscriber.
+	LagDisconnect
+)
+
+// NewBroadcaster returns a Broadcaster retaining the last size items.
+func NewBroadcaster[F any](size int) *Broadcaster[F] {
+	return &Broadcaster[F]{
+		ring: NewRingBuf[F](size, WithOverwrite[F]()),
+		subs: map[*Subscriber[F]]struct{}{},
+	}
+}
+
+// Broadcaster fans the published items out to any number of subscribers,
+// each reading at its own pace from a shared RingBuf.
+type Broadcaster[F any] struct {
+	mu     sync.Mutex
+	ring   *RingBuf[F]
+	subs   map[*Subscriber[F]]struct{}
+	wait   chan struct{} // closed on the next publish or read, created on demand
+	closed bool
+}
+
+// Subscriber reads the items of a Broadcaster published after Subscribe.
+type Subscriber[F any] struct {
+	b      *Broadcaster[F]
+	policy LagPolicy
+	onGap  func(from, to Position)
+	pos    Position
+	err    error
+}
+
+// Subscribe returns a Subscriber reading from the next published item on.
+// With LagSkip, onGap (if not nil) is called by Next with the skipped range
+// [from, to).
+func (b *Broadcaster[F]) Subscribe(policy LagPolicy, onGap func(from, to Position)) *Subscriber[F] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := &Subscriber[F]{
+		b:      b,
+		policy: policy,
+		onGap:  onGap,
+		pos:    b.ring.NextPosition(),
+	}
+	if b.closed {
+		s.err = io.EOF
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Publish appends item and returns its position. It blocks while a LagBlock
+// subscriber has not read the item it would overwrite, until ctx is done.
+func (b *Broadcaster[F]) Publish(ctx context.Context, item F) (Position, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.blocked() {
+		wait := b.changed()
+		b.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			b.mu.Lock()
+			return b.ring.NextPosition(), ctx.Err()
+		case <-wait:
+		}
+		b.mu.Lock()
+	}
+	pos := b.ring.NextPosition()
+	if b.closed {
+		return pos, fmt.Errorf("%w: broadcaster closed", ErrInvalidState)
+	}
+	if err := b.ring.Append(item); err != nil {
+		return pos, err
+	}
+	first := b.ring.FirstPosition()
+	for s := range b.subs {
+		if s.policy == LagDisconnect && s.pos-first < 0 {
+			s.err = ErrSlowSubscriber
+			delete(b.subs, s)
+		}
+	}
+	b.notify()
+	return pos, nil
+}
+
+// blocked reports whether the next Publish would overwrite an item that a
+// LagBlock subscriber has not read. b.mu must be held.
+func (b *Broadcaster[F]) blocked() bool {
+	if b.closed || 0 < b.ring.Free() {
+		return false
+	}
+	oldest := b.ring.FirstPosition()
+	for s := range b.subs {
+		if s.policy == LagBlock && s.pos-oldest <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops publishing. Subscribers read the remaining items and then get
+// io.EOF.
+func (b *Broadcaster[F]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.notify()
+}
+
+// changed returns a channel closed on the next publish or read. b.mu must be
+// held.
+func (b *Broadcaster[F]) changed() <-chan struct{} {
+	if b.wait == nil {
+		b.wait = make(chan struct{})
+	}
+	return b.wait
+}
+
+// notify wakes up the goroutines waiting on changed. b.mu must be held.
+func (b *Broadcaster[F]) notify() {
+	if b.wait != nil {
+		close(b.wait)
+		b.wait = nil
+	}
+}
+
+// Next returns the next item with its position, blocking until it is
+// published or ctx is done. It returns io.EOF once the Broadcaster or the
+// Subscriber is closed and ErrSlowSubscriber once a LagDisconnect subscriber
+// has fallen behind.
+func (s *Subscriber[F]) Next(ctx context.Context) (F, Position, error) {
+	b := s.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for {
+		var zero F
+		if s.err != nil {
+			return zero, s.pos, s.err
+		}
+		if first := b.ring.FirstPosition(); s.pos-first < 0 {
+			from := s.pos
+			s.pos = first
+			if s.onGap != nil {
+				b.mu.Unlock()
+				s.onGap(from, first)
+				b.mu.Lock()
+			}
+			continue
+		}
+		if s.pos-b.ring.NextPosition() < 0 {
+			item, err := b.ring.Get(s.pos)
+			if err != nil {
+				return zero, s.pos, err
+			}
+			pos := s.pos
+			s.pos++
+			b.notify()
+			return item, pos, nil
+		}
+		if b.closed {
+			return zero, s.pos, io.EOF
+		}
+		wait := b.changed()
+		b.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			b.mu.Lock()
+			return zero, s.pos, ctx.Err()
+		case <-wait:
+		}
+		b.mu.Lock()
+	}
+}
+
+// Lag returns the number of published items s has yet to read, including
+// those it would skip.
+func (s *Subscriber[F]) Lag() int {
+	b := s.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int(b.ring.NextPosition() - s.pos)
+}
+
+// Close unsubscribes s, so that it no longer blocks Publish.
+func (s *Subscriber[F]) Close() {
+	b := s.b
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.err == nil {
+		s.err = io.EOF
+	}
+	delete(b.subs, s)
+	b.notify()
+}
@@ -0,0 +1,177 @@
+package ringbuf
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroadcaster(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroadcaster[int](2)
+	s1 := b.Subscribe(LagBlock, nil)
+	s2 := b.Subscribe(LagBlock, nil)
+	for i := 0; i < 2; i++ {
+		pos, err := b.Publish(ctx, i)
+		assert.NoError(t, err)
+		assert.Equal(t, Position(i), pos)
+	}
+	for _, s := range []*Subscriber[int]{s1, s2} {
+		for i := 0; i < 2; i++ {
+			item, pos, err := s.Next(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, i, item)
+			assert.Equal(t, Position(i), pos)
+		}
+	}
+
+	b.Close()
+	_, _, err := s1.Next(ctx)
+	assert.Equal(t, io.EOF, err)
+	_, err = b.Publish(ctx, 2)
+	assert.True(t, errors.Is(err, ErrInvalidState))
+	_, _, err = b.Subscribe(LagSkip, nil).Next(ctx)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestBroadcasterBlock(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroadcaster[int](2)
+	s := b.Subscribe(LagBlock, nil)
+	skipper := b.Subscribe(LagSkip, nil)
+	for i := 0; i < 2; i++ {
+		_, err := b.Publish(ctx, i)
+		assert.NoError(t, err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err := b.Publish(timeout, 2)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	done := make(chan error)
+	go func() {
+		_, err := b.Publish(ctx, 2)
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatal("Publish returned before the subscriber read")
+	case <-time.After(10 * time.Millisecond):
+	}
+	item, _, err := s.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, item)
+	assert.NoError(t, <-done)
+
+	s.Close()
+	_, _, err = s.Next(ctx)
+	assert.Equal(t, io.EOF, err)
+	for i := 3; i < 6; i++ {
+		_, err := b.Publish(ctx, i)
+		assert.NoError(t, err)
+	}
+	item, pos, err := skipper.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, item)
+	assert.Equal(t, Position(4), pos)
+}
+
+func TestBroadcasterSkip(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroadcaster[int](2)
+	var gaps []PositionRange
+	s := b.Subscribe(LagSkip, func(from, to Position) {
+		gaps = append(gaps, PositionRange{Start: from, End: to})
+	})
+	for i := 0; i < 5; i++ {
+		_, err := b.Publish(ctx, i)
+		assert.NoError(t, err)
+	}
+	item, pos, err := s.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, item)
+	assert.Equal(t, Position(3), pos)
+	assert.Equal(t, []PositionRange{{Start: 0, End: 3}}, gaps)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	item, _, err = s.Next(timeout)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, item)
+	_, _, err = s.Next(timeout)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestBroadcasterDisconnect(t *testing.T) {
+	ctx := context.Background()
+	b := NewBroadcaster[int](2)
+	s := b.Subscribe(LagDisconnect, nil)
+	for i := 0; i < 2; i++ {
+		_, err := b.Publish(ctx, i)
+		assert.NoError(t, err)
+	}
+	item, _, err := s.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, item)
+	for i := 2; i < 4; i++ {
+		_, err := b.Publish(ctx, i)
+		assert.NoError(t, err)
+	}
+	_, _, err = s.Next(ctx)
+	assert.True(t, errors.Is(err, ErrSlowSubscriber))
+	assert.Equal(t, 0, len(b.subs))
+}
+
+func TestBroadcasterConcurrent(t *testing.T) {
+	const subscribers, n = 4, 1000
+	ctx := context.Background()
+	b := NewBroadcaster[int](8)
+	results := make(chan []int)
+	for k := 0; k < subscribers; k++ {
+		s := b.Subscribe(LagBlock, nil)
+		go func() {
+			var items []int
+			for {
+				item, _, err := s.Next(ctx)
+				if err != nil {
+					results <- items
+					return
+				}
+				items = append(items, item)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		_, err := b.Publish(ctx, i)
+		assert.NoError(t, err)
+	}
+	b.Close()
+	for k := 0; k < subscribers; k++ {
+		items := <-results
+		assert.Equal(t, n, len(items))
+		for i, item := range items {
+			if !assert.Equal(t, i, item) {
+				break
+			}
+		}
+	}
+}
+
+func TestSubscriberLag(t *testing.T) {
+	b := NewBroadcaster[int](4)
+	s := b.Subscribe(LagSkip, nil)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := b.Publish(ctx, i)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 3, s.Lag())
+	_, _, err := s.Next(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, s.Lag())
+}
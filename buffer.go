@@ -1,116 +1,1469 @@
 package ringbuf
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"sync"
+	"time"
+	"unsafe"
 )
 
 var (
-	ErrOutOfRange     = errors.New("out of range")
-	ErrBufferOverflow = errors.New("buffer overflow")
-	ErrInvalidState   = errors.New("invalid state")
+	ErrOutOfRange      = errors.New("out of range")
+	ErrBufferOverflow  = errors.New("buffer overflow")
+	ErrBufferUnderflow = errors.New("buffer underflow")
+	ErrInvalidState    = errors.New("invalid state")
+
+	ErrConcurrentModification = errors.New("concurrent modification")
+	ErrSlowSubscriber         = errors.New("slow subscriber")
+	ErrSealed                 = errors.New("sealed")
+	ErrDropRegression         = errors.New("drop regression")
 )
 
+// Position is compared only by differences and wraps around, so a wider
+// sequence number (e.g. int64 or uint64) can be truncated to Position as long
+// as the buffer size stays below 1<<31.
+//
+// Every bounds check thus follows the serial number arithmetic of RFC 1982
+// with 32 bits, as TCP sequence numbers do, and a uint32 sequence number can
+// be converted to Position as is: Position(seq). RingBuf64 takes and returns
+// 64-bit positions instead.
 type Position = int32
 
+// ComparePositions returns -1, 0 or +1 if a is before, at or after b,
+// assuming that they are less than 1<<31 apart, as in a buffer.
+func ComparePositions(a, b Position) int {
+	switch d := a - b; {
+	case d < 0:
+		return -1
+	case 0 < d:
+		return 1
+	}
+	return 0
+}
+
+// PositionDistance returns the number of positions from a to b, which is
+// negative if b is before a.
+func PositionDistance(a, b Position) int {
+	return int(b - a)
+}
+
+// PositionRange is the range of positions [Start, End).
+type PositionRange struct {
+	Start Position
+	End   Position
+}
+
+// Buffer is a sequence of items addressed by Position, appended at the end
+// and dropped from the front.
 type Buffer[F any] interface {
-	Drop(i Position) error
+	Appender[F]
+	Dropper
+	Reader[F]
+	Clone() Buffer[F]
+
+	// Roll drops up to drop and then appends items. If drop is out of range
+	// or the items do not fit once it is applied, it returns an error without
+	// changing the buffer. A SyncBuf does both under one lock, while with the
+	// lock-free buffers a concurrent Append may take the room checked for.
+	Roll(drop Position, items ...F) error
+
+	// MemoryUsage returns the estimated size in bytes of the backing storage,
+	// not counting the memory referenced by the items, see EstimateMemory.
+	MemoryUsage() int64
+}
+
+// EstimateMemory returns MemoryUsage of b plus the cost in bytes of each
+// retained item, e.g. the length of a []byte.
+func EstimateMemory[F any](b Buffer[F], cost func(item F) int) int64 {
+	n := b.MemoryUsage()
+	items, err := b.ToSlice(b.FirstPosition())
+	if err != nil {
+		return n
+	}
+	for _, item := range items {
+		n += int64(cost(item))
+	}
+	return n
+}
+
+// sizeOf returns the size in bytes of an F.
+func sizeOf[F any]() int64 {
+	var zero F
+	return int64(unsafe.Sizeof(zero))
+}
+
+// Appender appends items to a Buffer.
+type Appender[F any] interface {
 	Append(item F) error
+	AppendPos(item F) (Position, error)
+}
+
+// Dropper releases the items of a Buffer.
+type Dropper interface {
+	// Drop drops the items up to and including i. It returns an
+	// OutOfRangeError from the first position if i is not below the next
+	// position. Dropping up to the last dropped position again is a no-op,
+	// and so is dropping below it, except that a RingBuf, and the buffers
+	// built on one, return an error matching ErrDropRegression then; see
+	// RingBuf.DropIfHigher to ignore such drops.
+	Drop(i Position) error
+	Reset()
+	ResetAt(start Position)
+}
+
+// Reader reads the items of a Buffer without modifying it.
+type Reader[F any] interface {
 	Iterator(start Position) (*Iterator[F], error)
 	ToSlice(start Position) ([]F, error)
+	Bounds() (low Position, high Position)
+	Len() int
+	Cap() int
+	Free() int
+	IsEmpty() bool
+	IsFull() bool
+	Get(pos Position) (F, error)
+	ToSliceRange(start, end Position) ([]F, error)
+	CopyTo(dst []F, start Position) (int, error)
+	FirstPosition() Position
+	NextPosition() Position
+	ToSliceN(start Position, max int) ([]F, error)
+	Stats() Stats
 }
 
-func NewRingBuf[F any](size int) *RingBuf[F] {
-	return &RingBuf[F]{
+func NewRingBuf[F any](size int, opts ...Option[F]) *RingBuf[F] {
+	return NewRingBufAt[F](size, 0, opts...)
+}
+
+// NewRingBufAt returns an empty RingBuf whose first appended item is at
+// start, e.g. the initial sequence number of a protocol.
+func NewRingBufAt[F any](size int, start Position, opts ...Option[F]) *RingBuf[F] {
+	b := &RingBuf[F]{
 		buf:  make([]F, size),
-		drop: -1,
-		base: Position(-size),
+		drop: start - 1,
+		base: start - Position(size),
 		next: size,
 	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// NewRingBufPow2 returns a RingBuf whose size is rounded up to a power of two
+// so that the slot of a position, on Append as well as on Get and Set, is
+// computed with a bit mask instead of a modulo or a split range check.
+func NewRingBufPow2[F any](size int, opts ...Option[F]) *RingBuf[F] {
+	pow2 := 1
+	for pow2 < size {
+		pow2 <<= 1
+	}
+	b := NewRingBuf[F](pow2, opts...)
+	b.mask = pow2 - 1
+	return b
+}
+
+type Option[F any] func(b *RingBuf[F])
+
+// WithOverwrite makes Append drop the oldest item instead of returning
+// ErrBufferOverflow when the buffer is full.
+func WithOverwrite[F any]() Option[F] {
+	return func(b *RingBuf[F]) {
+		b.overwrite = true
+	}
+}
+
+// WithOnAppend registers fn to be called with each appended item.
+func WithOnAppend[F any](fn func(pos Position, item F)) Option[F] {
+	return func(b *RingBuf[F]) {
+		b.onAppend = fn
+	}
+}
+
+// WithOnDrop registers fn to be called with each item released by Drop,
+// Reset or ResetAt.
+func WithOnDrop[F any](fn func(pos Position, item F)) Option[F] {
+	return func(b *RingBuf[F]) {
+		b.onDrop = fn
+	}
+}
+
+// WithOnEvict registers fn to be called with each item dropped by an
+// overwriting append.
+func WithOnEvict[F any](fn func(pos Position, item F)) Option[F] {
+	return func(b *RingBuf[F]) {
+		b.onEvict = fn
+	}
+}
+
+// WithOnEvictBatch registers fn to be called with the items dropped by an
+// overwriting append, one call per contiguous run starting at start. items
+// aliases the buffer and is only valid during the call.
+func WithOnEvictBatch[F any](fn func(start Position, items []F)) Option[F] {
+	return func(b *RingBuf[F]) {
+		b.onEvictBatch = fn
+	}
+}
+
+// WithZeroing makes Drop clear the slots of dropped items so that anything
+// they reference can be garbage collected. Dropped items then read as the
+// zero value until their slots are reused.
+func WithZeroing[F any]() Option[F] {
+	return func(b *RingBuf[F]) {
+		b.zeroing = true
+	}
+}
+
+// WithCodec sets the codec used by Snapshot and Restore.
+func WithCodec[F any](codec Codec[F]) Option[F] {
+	return func(b *RingBuf[F]) {
+		b.codec = codec
+	}
+}
+
+// WithMaxRestoreSize makes Restore, Decode and UnmarshalBinary reject a
+// snapshot of a buffer larger than size items, which is 1<<24 by default, so
+// that a corrupt header cannot force a huge allocation.
+func WithMaxRestoreSize[F any](size int) Option[F] {
+	return func(b *RingBuf[F]) {
+		b.maxRestore = size
+	}
+}
+
+// defaultMaxRestoreSize is the largest capacity restored without
+// WithMaxRestoreSize.
+const defaultMaxRestoreSize = 1 << 24
+
+type RingBuf[F any] struct {
+	stats     counters
+	drop      Position
+	buf       []F
+	base      Position
+	next      int
+	mask      int // len(buf)-1 if len(buf) is a power of two, or 0
+	overwrite bool
+	zeroing   bool
+	sealed    bool
+	onAppend  func(pos Position, item F)
+	onDrop    func(pos Position, item F)
+	onEvict   func(pos Position, item F)
+
+	onEvictBatch func(start Position, items []F)
+	codec        Codec[F]
+	maxRestore   int    // largest capacity restored, or 0 for the default
+	holes        []bool // slots skipped by InsertAt, allocated on first use
+	gen          uint64 // number of mutations, checked by iterators
+	logger       *logLimiter
+}
+
+// Drop drops the items up to and including drop. It returns an
+// OutOfRangeError if drop is not below the next position, and an error
+// matching ErrDropRegression if it is below the last dropped position.
+func (b *RingBuf[F]) Drop(drop Position) error {
+	if err := b.checkDrop(drop); err != nil {
+		return err
+	}
+	if b.onDrop != nil {
+		b.visit(b.drop+1, drop+1, b.onDrop)
+	}
+	if b.zeroing {
+		b.zero(b.drop+1, drop+1)
+	}
+	b.stats.dropped(int(drop - b.drop))
+	b.logDrop(drop, int(drop-b.drop))
+	b.drop = drop
+	b.modified()
+	return nil
+}
+
+// checkDrop returns the error Drop fails with for drop, if any.
+func (b *RingBuf[F]) checkDrop(drop Position) error {
+	if b.next <= int(drop-b.base) { // b.base + b.next <= drop
+		return b.stats.fail(errOutOfRange(drop, b.FirstPosition(), b.NextPosition()))
+	}
+	if drop-b.drop < 0 {
+		return fmt.Errorf("%w: %v below %v", ErrDropRegression, drop, b.drop)
+	}
+	return nil
+}
+
+// DropIfHigher is like Drop but does nothing if drop is at or below the last
+// dropped position, so that acks delivered out of order can be applied as
+// they come.
+func (b *RingBuf[F]) DropIfHigher(drop Position) error {
+	if drop-b.drop <= 0 {
+		return nil
+	}
+	return b.Drop(drop)
+}
+
+// Seal makes the appends fail with ErrSealed from now on, while the items can
+// still be read and dropped. Reset and ResetAt do not unseal the buffer.
+func (b *RingBuf[F]) Seal() {
+	b.sealed = true
+}
+
+// Sealed reports whether Seal has been called.
+func (b *RingBuf[F]) Sealed() bool {
+	return b.sealed
+}
+
+// DropN drops the oldest n items, or returns ErrOutOfRange if there are fewer.
+func (b *RingBuf[F]) DropN(n int) error {
+	if n <= 0 {
+		return nil
+	}
+	return b.Drop(b.FirstPosition() + Position(n) - 1)
+}
+
+// DropAll drops all items.
+func (b *RingBuf[F]) DropAll() error {
+	return b.Drop(b.NextPosition() - 1)
+}
+
+// DropWhile drops the oldest items while fn returns true for them, and
+// returns the number of dropped items.
+func (b *RingBuf[F]) DropWhile(fn func(pos Position, item F) bool) int {
+	n := countWhile[F](b, fn)
+	b.DropN(n)
+	return n
+}
+
+// countWhile returns the number of the oldest items fn returns true for.
+func countWhile[F any](b Buffer[F], fn func(pos Position, item F) bool) int {
+	iter, err := b.Iterator(b.FirstPosition())
+	if err != nil {
+		return 0
+	}
+	n := 0
+	for iter.Scan() && fn(iter.Position(), iter.Item()) {
+		n++
+	}
+	return n
+}
+
+// PopFront drops the oldest item and returns it with its position, or
+// returns ErrBufferUnderflow if the buffer is empty.
+func (b *RingBuf[F]) PopFront() (F, Position, error) {
+	return popFront[F](b)
+}
+
+// Oldest returns the oldest item with its position, or returns
+// ErrBufferUnderflow if the buffer is empty.
+func (b *RingBuf[F]) Oldest() (F, Position, error) {
+	return peek[F](b, b.FirstPosition())
+}
+
+// Newest returns the last appended item with its position, or returns
+// ErrBufferUnderflow if the buffer is empty.
+func (b *RingBuf[F]) Newest() (F, Position, error) {
+	return peek[F](b, b.NextPosition()-1)
+}
+
+func peek[F any](b Buffer[F], pos Position) (F, Position, error) {
+	if b.Len() == 0 {
+		var zero F
+		return zero, pos, ErrBufferUnderflow
+	}
+	item, err := b.Get(pos)
+	return item, pos, err
+}
+
+// roll is Roll of b made of Drop and Append, checking with checkRoll that
+// both succeed before doing either.
+func roll[F any](b Buffer[F], drop Position, items []F) error {
+	if err := checkRoll(b, drop, len(items)); err != nil {
+		return err
+	}
+	return applyRoll(b, drop, items)
+}
+
+// checkRoll returns the error Roll of n items fails with before changing b:
+// ErrSealed, ErrOutOfRange if drop is not below the next position, or
+// ErrBufferOverflow if the items do not fit once drop is applied and b does
+// not overwrite.
+func checkRoll[F any](b Buffer[F], drop Position, n int) error {
+	if s, ok := b.(interface{ Sealed() bool }); ok && s.Sealed() {
+		return ErrSealed
+	}
+	first, next := b.FirstPosition(), b.NextPosition()
+	if 0 <= drop-next {
+		return errOutOfRange(drop, first, next)
+	}
+	if overwrites(b) {
+		return nil
+	}
+	free := b.Free()
+	if 0 <= drop-first {
+		free += int(drop-first) + 1
+	}
+	if free < n {
+		return errOverflow(first, b.Cap())
+	}
+	return nil
+}
+
+// applyRoll drops up to drop and appends items, stopping at the first error.
+func applyRoll[F any](b Buffer[F], drop Position, items []F) error {
+	if err := b.Drop(drop); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := b.Append(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// overwrites reports whether appending to b drops the oldest items instead of
+// failing with ErrBufferOverflow, which decorators forward.
+func overwrites[F any](b Buffer[F]) bool {
+	o, ok := b.(interface{ overwrites() bool })
+	return ok && o.overwrites()
+}
+
+func popFront[F any](b Buffer[F]) (F, Position, error) {
+	item, pos, err := peek(b, b.FirstPosition())
+	if err != nil {
+		return item, pos, err
+	}
+	return item, pos, b.Drop(pos)
+}
+
+// evict drops up to drop on behalf of an overwriting append.
+func (b *RingBuf[F]) evict(drop Position) {
+	if b.onEvict != nil {
+		b.visit(b.drop+1, drop+1, b.onEvict)
+	}
+	if b.onEvictBatch != nil {
+		from, to := b.drop+1, drop+1
+		low, high := b.Bounds()
+		if from-low < 0 {
+			from = low
+		}
+		if 0 < to-high {
+			to = high
+		}
+		if 0 < to-from {
+			head, tail, _ := b.iterRange(from, to)
+			if len(head) > 0 {
+				b.onEvictBatch(from, head)
+			}
+			if len(tail) > 0 {
+				b.onEvictBatch(from+Position(len(head)), tail)
+			}
+		}
+	}
+	b.stats.dropped(int(drop - b.drop))
+	b.drop = drop
+}
+
+// visit calls fn with each item in [from, to) that is still in the buffer.
+func (b *RingBuf[F]) visit(from, to Position, fn func(Position, F)) {
+	for pos := from; pos-to < 0; pos++ { // pos < to
+		if i, ok := b.slot(pos); ok {
+			fn(pos, b.buf[i])
+		}
+	}
+}
+
+// zero clears the slots of the items in [from, to) that are still in the
+// buffer.
+func (b *RingBuf[F]) zero(from, to Position) {
+	var zero F
+	for pos := from; pos-to < 0; pos++ { // pos < to
+		if i, ok := b.slot(pos); ok {
+			b.buf[i] = zero
+		}
+	}
+}
+
+// Roll drops up to drop and then appends items with AppendAll. Neither is
+// done if drop is out of range, if the items do not fit once it is applied,
+// or if the buffer is sealed.
+func (b *RingBuf[F]) Roll(drop Position, items ...F) error {
+	if err := b.checkRollCap(drop, len(items), len(b.buf)); err != nil {
+		return err
+	}
+	if err := b.Drop(drop); err != nil {
+		return err
+	}
+	_, err := b.AppendAll(items)
+	return err
+}
+
+// checkRollCap is checkRoll for a RingBuf that can hold up to capacity
+// items.
+func (b *RingBuf[F]) checkRollCap(drop Position, n, capacity int) error {
+	if b.sealed {
+		return ErrSealed
+	}
+	if err := b.checkDrop(drop); err != nil {
+		return err
+	}
+	free := capacity - b.Len() + int(drop-b.drop)
+	if !b.overwrite && free < n {
+		return b.errOverflow()
+	}
+	return nil
+}
+
+func (b *RingBuf[F]) overwrites() bool {
+	return b.overwrite
+}
+
+func (b *RingBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+// AppendPos appends item and returns its position. On failure it returns the
+// position the item would have had.
+func (b *RingBuf[F]) AppendPos(item F) (Position, error) {
+	if b.sealed {
+		return b.NextPosition(), ErrSealed
+	}
+	size := len(b.buf)
+	if size < int(b.base-b.drop)+b.next { // drop + len(buf) < b.base + b.next
+		if !b.overwrite || size == 0 {
+			return b.NextPosition(), b.errOverflow()
+		}
+		b.evict(b.base + Position(b.next-size))
+	}
+	next := b.wrap(b.next)
+	if next == 0 {
+		b.base += Position(size)
+	}
+	b.buf[next] = item
+	if b.holes != nil {
+		b.holes[next] = false
+	}
+	b.next = next + 1
+	pos := b.base + Position(next)
+	if b.onAppend != nil {
+		b.onAppend(pos, item)
+	}
+	b.stats.appended(1, b.Len())
+	b.modified()
+	return pos, nil
+}
+
+// AppendEvict appends item, evicting the oldest item first if the buffer is
+// full even without WithOverwrite, and returns the evicted item so that the
+// caller can recycle it. A sealed buffer or one of size 0 does not append
+// anything.
+func (b *RingBuf[F]) AppendEvict(item F) (evicted F, evictedPos Position, didEvict bool) {
+	if b.Free() == 0 && 0 < len(b.buf) && !b.sealed {
+		evictedPos = b.FirstPosition()
+		evicted, _ = b.Get(evictedPos)
+		didEvict = true
+		b.evict(evictedPos)
+	}
+	_ = b.Append(item)
+	return evicted, evictedPos, didEvict
+}
+
+func (b *RingBuf[F]) AppendAll(items []F) (Position, error) {
+	size := len(b.buf)
+	start := b.base + Position(b.next)
+	if b.sealed {
+		return start, ErrSealed
+	}
+	if len(items) == 0 {
+		return start, nil
+	}
+	if b.Free() < len(items) {
+		if !b.overwrite || size == 0 {
+			return start, b.errOverflow()
+		}
+		b.evict(start + Position(len(items)-size-1))
+		if size < len(items) {
+			// items that do not fit are evicted as soon as they are appended
+			skipped := items[:len(items)-size]
+			for i, item := range skipped {
+				if b.onAppend != nil {
+					b.onAppend(start+Position(i), item)
+				}
+				if b.onEvict != nil {
+					b.onEvict(start+Position(i), item)
+				}
+			}
+			if b.onEvictBatch != nil {
+				b.onEvictBatch(start, skipped)
+			}
+			b.skip(len(skipped))
+			items = items[len(skipped):]
+		}
+	}
+	first := b.base + Position(b.next)
+	next := b.wrap(b.next)
+	if next == 0 {
+		b.base += Position(size)
+	}
+	n := copy(b.buf[next:], items)
+	b.next = next + n
+	if n < len(items) {
+		b.base += Position(size)
+		b.next = copy(b.buf, items[n:])
+	}
+	if b.holes != nil {
+		for i := range items {
+			b.holes[b.wrap(next+i)] = false
+		}
+	}
+	if b.onAppend != nil {
+		for i, item := range items {
+			b.onAppend(first+Position(i), item)
+		}
+	}
+	b.stats.appended(int(b.NextPosition()-start), b.Len())
+	b.modified()
+	return start, nil
+}
+
+func (b *RingBuf[F]) wrap(i int) int {
+	if b.mask != 0 {
+		return i & b.mask
+	}
+	return i % len(b.buf)
+}
+
+// skip advances the next position by n without writing any item.
+func (b *RingBuf[F]) skip(n int) {
+	upper := b.base + Position(b.next+n)
+	b.next = b.wrap(b.next+n-1) + 1
+	b.base = upper - Position(b.next)
+}
+
+// Resize moves the retained items into a new backing array of size items. It
+// returns ErrBufferOverflow if they do not fit.
+func (b *RingBuf[F]) Resize(size int) error {
+	if size <= 0 || size < b.Len() {
+		return errOverflow(b.FirstPosition(), size)
+	}
+	b.resize(size)
+	return nil
+}
+
+func (b *RingBuf[F]) resize(size int) {
+	first := b.FirstPosition()
+	buf := make([]F, size)
+	n, _ := b.CopyTo(buf, first)
+	var holes []bool
+	if b.holes != nil {
+		holes = make([]bool, size)
+		for i := 0; i < n; i++ {
+			holes[i] = !b.Has(first + Position(i))
+		}
+	}
+	b.load(buf, first, n)
+	b.holes = holes
+}
+
+// load replaces the backing array with buf, whose first n slots hold the
+// items from first.
+func (b *RingBuf[F]) load(buf []F, first Position, n int) {
+	size := len(buf)
+	b.buf = buf
+	b.holes = nil
+	if b.mask != 0 {
+		b.mask = 0
+		if size&(size-1) == 0 {
+			b.mask = size - 1
+		}
+	}
+	b.drop = first - 1
+	if n == 0 {
+		b.base = first - Position(size)
+		b.next = size
+	} else {
+		b.base = first
+		b.next = n
+	}
+	b.modified()
+}
+
+func (b *RingBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+// ResetAt empties the buffer so that the next Append is assigned start.
+func (b *RingBuf[F]) ResetAt(start Position) {
+	if b.onDrop != nil {
+		b.visit(b.drop+1, b.NextPosition(), b.onDrop)
+	}
+	b.stats.dropped(b.Len())
+	var zero F
+	for i := range b.buf {
+		b.buf[i] = zero
+	}
+	b.holes = nil
+	b.drop = start - 1
+	b.base = start - Position(len(b.buf))
+	b.next = len(b.buf)
+	b.modified()
+}
+
+// Validate checks the internal invariants of the buffer and returns an error
+// wrapping ErrInvalidState if one does not hold. Building with the
+// ringbuf_debug tag validates after each mutation and panics on failure.
+func (b *RingBuf[F]) Validate() error {
+	size := len(b.buf)
+	switch {
+	case size == 0 && b.next != 0, size != 0 && (b.next < 1 || size < b.next):
+		return fmt.Errorf("%w: next %v not in [1, %v]", ErrInvalidState, b.next, size)
+	case b.Len() < 0 || size < b.Len():
+		return fmt.Errorf("%w: drop %v, base %v, next %v", ErrInvalidState, b.drop, b.base, b.next)
+	case b.mask != 0 && (b.mask != size-1 || size&b.mask != 0):
+		return fmt.Errorf("%w: mask %v for size %v", ErrInvalidState, b.mask, size)
+	case b.holes != nil && len(b.holes) != size:
+		return fmt.Errorf("%w: %v holes for size %v", ErrInvalidState, len(b.holes), size)
+	}
+	return nil
+}
+
+// modified invalidates the iterators and validates the invariants after a
+// mutation.
+func (b *RingBuf[F]) modified() {
+	b.gen++
+	b.check()
+}
+
+func (b *RingBuf[F]) check() {
+	if debug {
+		if err := b.Validate(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (b *RingBuf[F]) String() string {
+	return describe[F]("RingBuf", b)
+}
+
+func describe[F any](name string, b Buffer[F]) string {
+	low, high := b.Bounds()
+	return fmt.Sprintf("%v{first: %v, next: %v, bounds: [%v, %v), len: %v, cap: %v}",
+		name, b.FirstPosition(), b.NextPosition(), low, high, b.Len(), b.Cap())
+}
+
+// Dump writes the internal layout of the buffer to w: the raw fields and, for
+// each slot, the position it holds and whether that item is retained,
+// dropped or a hole left by InsertAt.
+func (b *RingBuf[F]) Dump(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "drop %v, base %v, next %v, mask %v, size %v\n", b.drop, b.base, b.next, b.mask, len(b.buf)); err != nil {
+		return err
+	}
+	for i := range b.buf {
+		pos := b.base + Position(i)
+		if b.next <= i {
+			pos -= Position(len(b.buf)) // written before the last wrap
+		}
+		state := "retained"
+		if pos-b.drop <= 0 {
+			state = "dropped"
+		} else if b.holes != nil && b.holes[i] {
+			state = "hole"
+		}
+		mark := ""
+		if i == b.next%len(b.buf) {
+			mark = " <- next append"
+		}
+		if _, err := fmt.Fprintf(w, "slot %v: pos %v %v%v\n", i, pos, state, mark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Iterator returns an Iterator from start that aliases the backing array.
+// Once the buffer is modified, its Scan returns false and its Err returns
+// ErrConcurrentModification.
+func (b *RingBuf[F]) Iterator(start Position) (*Iterator[F], error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return nil, err
+	}
+	iter := NewIteratorAt[F](start, head, tail)
+	iter.gen, iter.want = &b.gen, b.gen
+	return iter, nil
+}
+
+func (b *RingBuf[F]) ToSlice(start Position) ([]F, error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return nil, err
+	}
+	return concat(head, tail), nil
+}
+
+func (b *RingBuf[F]) ToSliceRange(start, end Position) ([]F, error) {
+	head, tail, err := b.iterRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return concat(head, tail), nil
+}
+
+func (b *RingBuf[F]) CopyTo(dst []F, start Position) (int, error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return 0, err
+	}
+	n := copy(dst, head)
+	n += copy(dst[n:], tail)
+	return n, nil
+}
+
+func (b *RingBuf[F]) ToSliceN(start Position, max int) ([]F, error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return nil, err
+	}
+	head, tail = limit(head, tail, max)
+	return concat(head, tail), nil
+}
+
+// AppendToSlice appends the items from start to dst and returns the extended
+// slice.
+func (b *RingBuf[F]) AppendToSlice(dst []F, start Position) ([]F, error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return dst, err
+	}
+	return append(append(dst, head...), tail...), nil
+}
+
+// Tail returns the newest n items, or all of them if there are fewer.
+func (b *RingBuf[F]) Tail(n int) ([]F, error) {
+	return b.ToSlice(tailPosition[F](b, n))
+}
+
+// tailPosition returns the position of the n-th newest item, or the first
+// position if there are fewer.
+func tailPosition[F any](b Buffer[F], n int) Position {
+	if n < 0 {
+		n = 0
+	}
+	if b.Len() < n {
+		return b.FirstPosition()
+	}
+	return b.NextPosition() - Position(n)
+}
+
+// Views returns the items from start as two slices that alias the backing
+// array, so reading them does not allocate. The slices are only valid until
+// the next mutation of the buffer: an Append may overwrite items in place, and
+// writing to the slices modifies the buffer.
+func (b *RingBuf[F]) Views(start Position) (head, tail []F, err error) {
+	return b.iter(start)
+}
+
+// FindPosition returns the first retained position whose item satisfies pred,
+// or NextPosition if there is none. pred must be false for a prefix of the
+// items and true for the rest, e.g. a timestamp being at least t.
+func (b *RingBuf[F]) FindPosition(pred func(item F) bool) Position {
+	first := b.FirstPosition()
+	head, tail, _ := b.iter(first)
+	if i := sort.Search(len(head), func(i int) bool { return pred(head[i]) }); i < len(head) {
+		return first + Position(i)
+	}
+	i := sort.Search(len(tail), func(i int) bool { return pred(tail[i]) })
+	return first + Position(len(head)+i)
+}
+
+func (b *RingBuf[F]) iterRange(start, end Position) ([]F, []F, error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := int(end - start)
+	if n < 0 || len(head)+len(tail) < n {
+		return nil, nil, b.errOutOfRange(end)
+	}
+	head, tail = limit(head, tail, n)
+	return head, tail, nil
+}
+
+// concat returns a fresh slice of exactly the items of head and tail, so that
+// it neither aliases the backing array nor wastes capacity.
+func concat[F any](head, tail []F) []F {
+	ret := make([]F, len(head)+len(tail))
+	copy(ret[copy(ret, head):], tail)
+	return ret
+}
+
+func limit[F any](head, tail []F, n int) ([]F, []F) {
+	if n <= 0 {
+		return head[:0], nil
+	}
+	if n <= len(head) {
+		return head[:n], nil
+	}
+	if n-len(head) < len(tail) {
+		return head, tail[:n-len(head)]
+	}
+	return head, tail
+}
+
+func (b *RingBuf[F]) iter(start Position) ([]F, []F, error) {
+	if begin := start - b.base; 0 <= begin && begin <= Position(b.next) {
+		return b.buf[begin:b.next], nil, nil
+	}
+	if diff := int(b.base - start); 0 < diff && b.next+diff <= len(b.buf) {
+		begin := len(b.buf) - diff
+		return b.buf[begin:], b.buf[:b.next], nil
+	}
+	return nil, nil, b.errOutOfRange(start)
+}
+
+func (b *RingBuf[F]) Get(pos Position) (F, error) {
+	i, ok := b.slot(pos)
+	if !ok {
+		var zero F
+		return zero, b.errOutOfRange(pos)
+	}
+	return b.buf[i], nil
+}
+
+func (b *RingBuf[F]) Set(pos Position, item F) error {
+	i, ok := b.slot(pos)
+	if !ok {
+		return b.errOutOfRange(pos)
+	}
+	b.buf[i] = item
+	b.modified()
+	return nil
+}
+
+// InsertAt writes item at pos, which may be past the next position as long as
+// it is within the capacity from the first position. The positions skipped
+// over are holes that read as the zero value until they are inserted, see
+// Has.
+func (b *RingBuf[F]) InsertAt(pos Position, item F) error {
+	first, next := b.FirstPosition(), b.NextPosition()
+	if pos-first < 0 {
+		return b.errOutOfRange(pos)
+	}
+	if len(b.buf) <= int(pos-first) {
+		return b.errOverflow()
+	}
+	if pos-next < 0 {
+		i, _ := b.slot(pos)
+		if b.holes != nil && b.holes[i] {
+			b.holes[i] = false
+			b.stats.appended(1, b.Len())
+			if b.onAppend != nil {
+				b.onAppend(pos, item)
+			}
+		}
+		b.buf[i] = item
+		b.modified()
+		return nil
+	}
+	if b.sealed {
+		return ErrSealed
+	}
+	if n := int(pos - next); 0 < n {
+		if b.holes == nil {
+			b.holes = make([]bool, len(b.buf))
+		}
+		var zero F
+		for i := 0; i < n; i++ {
+			j := b.wrap(b.next + i)
+			b.buf[j] = zero
+			b.holes[j] = true
+		}
+		b.skip(n)
+	}
+	return b.Append(item)
+}
+
+// Contains reports whether pos is within Bounds, so that reading from pos does
+// not fail with ErrOutOfRange. Unlike Has, it includes the dropped items that
+// have not been overwritten yet.
+func (b *RingBuf[F]) Contains(pos Position) bool {
+	return contains[F](b, pos)
+}
+
+func contains[F any](b Buffer[F], pos Position) bool {
+	low, high := b.Bounds()
+	return 0 <= pos-low && pos-high < 0
+}
+
+// Has reports whether pos is retained and not a hole left by InsertAt.
+func (b *RingBuf[F]) Has(pos Position) bool {
+	if pos-b.FirstPosition() < 0 || 0 <= pos-b.NextPosition() {
+		return false
+	}
+	i, ok := b.slot(pos)
+	return ok && (b.holes == nil || !b.holes[i])
+}
+
+// Missing returns the ranges of holes left by InsertAt among the retained
+// positions, in position order.
+func (b *RingBuf[F]) Missing() []PositionRange {
+	if b.holes == nil {
+		return nil
+	}
+	var ranges []PositionRange
+	next := b.NextPosition()
+	for pos := b.FirstPosition(); pos-next < 0; pos++ { // pos < next
+		if b.Has(pos) {
+			continue
+		}
+		if n := len(ranges); 0 < n && ranges[n-1].End == pos {
+			ranges[n-1].End++
+		} else {
+			ranges = append(ranges, PositionRange{Start: pos, End: pos + 1})
+		}
+	}
+	return ranges
+}
+
+func (b *RingBuf[F]) slot(pos Position) (int, bool) {
+	if b.mask != 0 {
+		low := b.base - Position(len(b.buf)-b.next)
+		if i := pos - low; 0 <= i && i <= Position(b.mask) {
+			return int(pos-b.base) & b.mask, true
+		}
+		return 0, false
+	}
+	if i := pos - b.base; 0 <= i && i < Position(b.next) {
+		return int(i), true
+	}
+	if diff := int(b.base - pos); 0 < diff && b.next+diff <= len(b.buf) {
+		return len(b.buf) - diff, true
+	}
+	return 0, false
+}
+
+func (b *RingBuf[F]) errOutOfRange(pos Position) error {
+	bottom, upper := b.Bounds()
+	return b.stats.fail(errOutOfRange(pos, bottom, upper))
+}
+
+func (b *RingBuf[F]) Bounds() (Position, Position) {
+	low := b.base - Position(len(b.buf)-b.next)
+	high := b.base + Position(b.next)
+	return low, high
+}
+
+func (b *RingBuf[F]) FirstPosition() Position {
+	return b.drop + 1
+}
+
+func (b *RingBuf[F]) NextPosition() Position {
+	return b.base + Position(b.next)
+}
+
+func (b *RingBuf[F]) Clone() Buffer[F] {
+	buf := make([]F, len(b.buf))
+	copy(buf, b.buf)
+	var holes []bool
+	if b.holes != nil {
+		holes = make([]bool, len(b.holes))
+		copy(holes, b.holes)
+	}
+	return &RingBuf[F]{
+		stats:     b.stats.clone(),
+		drop:      b.drop,
+		buf:       buf,
+		base:      b.base,
+		next:      b.next,
+		mask:      b.mask,
+		overwrite: b.overwrite,
+		zeroing:   b.zeroing,
+		sealed:    b.sealed,
+		onAppend:  b.onAppend,
+		onDrop:    b.onDrop,
+		onEvict:   b.onEvict,
+
+		onEvictBatch: b.onEvictBatch,
+		codec:        b.codec,
+		maxRestore:   b.maxRestore,
+		holes:        holes,
+		logger:       b.logger.clone(),
+	}
+}
+
+// CloneFunc is like Clone but copies each retained item with copyItem, so
+// that the clone does not share what the items reference. Dropped items that
+// can still be read are shared.
+func (b *RingBuf[F]) CloneFunc(copyItem func(item F) F) Buffer[F] {
+	clone := b.Clone().(*RingBuf[F])
+	clone.copyItems(copyItem)
+	return clone
+}
+
+// copyItems replaces each retained item with its copy by copyItem.
+func (b *RingBuf[F]) copyItems(copyItem func(item F) F) {
+	head, tail, _ := b.iter(b.FirstPosition())
+	for _, items := range [][]F{head, tail} {
+		for i, item := range items {
+			items[i] = copyItem(item)
+		}
+	}
+}
+
+func (b *RingBuf[F]) Len() int {
+	return int(b.base-b.drop) + b.next - 1 // b.base + b.next - (drop + 1)
+}
+
+func (b *RingBuf[F]) Cap() int {
+	return len(b.buf)
+}
+
+func (b *RingBuf[F]) MemoryUsage() int64 {
+	return int64(len(b.buf))*sizeOf[F]() + int64(len(b.holes))
+}
+
+func (b *RingBuf[F]) Free() int {
+	return b.Cap() - b.Len()
+}
+
+func (b *RingBuf[F]) IsEmpty() bool {
+	return b.Len() <= 0
+}
+
+func (b *RingBuf[F]) IsFull() bool {
+	return b.Free() <= 0
+}
+
+func (b *RingBuf[F]) Stats() Stats {
+	return b.stats.stats(b.Len())
+}
+
+func NewSyncBuf[F any](buf Buffer[F]) *SyncBuf[F] {
+	return &SyncBuf[F]{
+		mu:  sync.RWMutex{},
+		buf: buf,
+	}
+}
+
+type SyncBuf[F any] struct {
+	mu      sync.RWMutex
+	buf     Buffer[F]
+	wait    chan struct{} // closed on the next mutation, created on demand
+	watches []watch       // pending channels of Notify and NotifyEvicted
+	pool    sync.Pool     // *[]F reused by ToSlicePooled
+	sealed  bool
+
+	// Iterators of a RingBuf alias its backing array from snapLow on, which
+	// is copied before a write to a slot they may read. Iterator.Close does
+	// not hold mu, so snapMu guards the fields below.
+	snapMu  sync.Mutex
+	snaps   int    // open iterators aliasing the backing array
+	snapGen uint64 // number of copies, so that stale iterators are not counted
+	snapLow Position
+}
+
+type watch struct {
+	pos     Position
+	ch      chan struct{}
+	evicted bool // closed once pos is before the first position instead
+}
+
+func (c *SyncBuf[F]) Drop(drop Position) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	return c.drop(drop)
+}
+
+// DropIfHigher is like Drop but does nothing if drop is at or below the last
+// dropped position. The check and the drop are done under one lock.
+func (c *SyncBuf[F]) DropIfHigher(drop Position) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if drop-c.buf.FirstPosition() < 0 {
+		return nil
+	}
+	defer c.notify()
+	return c.drop(drop)
+}
+
+// DropN drops the oldest n items, or returns ErrOutOfRange if there are fewer.
+func (c *SyncBuf[F]) DropN(n int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	if n <= 0 {
+		return nil
+	}
+	return c.drop(c.buf.FirstPosition() + Position(n) - 1)
+}
+
+// DropAll drops all items.
+func (c *SyncBuf[F]) DropAll() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	return c.drop(c.buf.NextPosition() - 1)
+}
+
+// DropWhile drops the oldest items while fn returns true for them, and
+// returns the number of dropped items. fn is called under the write lock.
+func (c *SyncBuf[F]) DropWhile(fn func(pos Position, item F) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	n := countWhile(c.buf, fn)
+	if 0 < n {
+		c.drop(c.buf.FirstPosition() + Position(n) - 1)
+	}
+	return n
+}
+
+// PopFront drops the oldest item and returns it with its position, or
+// returns ErrBufferUnderflow if the buffer is empty.
+func (c *SyncBuf[F]) PopFront() (F, Position, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	if ring, ok := c.buf.(*RingBuf[F]); ok && ring.zeroing {
+		c.cow(ring.FirstPosition())
+	}
+	return popFront(c.buf)
+}
+
+// Oldest returns the oldest item with its position, or returns
+// ErrBufferUnderflow if the buffer is empty.
+func (c *SyncBuf[F]) Oldest() (F, Position, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return peek(c.buf, c.buf.FirstPosition())
+}
+
+// Newest returns the last appended item with its position, or returns
+// ErrBufferUnderflow if the buffer is empty.
+func (c *SyncBuf[F]) Newest() (F, Position, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return peek(c.buf, c.buf.NextPosition()-1)
+}
+
+func (c *SyncBuf[F]) drop(drop Position) error {
+	if ring, ok := c.buf.(*RingBuf[F]); ok && ring.zeroing {
+		c.cow(drop) // zeroing the slots up to drop
+	}
+	return c.buf.Drop(drop)
+}
+
+// Roll calls Roll of the underlying buffer under one lock, so that no reader
+// sees the buffer in between. A sealed buffer neither drops nor appends
+// anything.
+func (c *SyncBuf[F]) Roll(drop Position, items ...F) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	if c.sealed {
+		return ErrSealed
+	}
+	if ring, ok := c.buf.(*RingBuf[F]); ok {
+		// the slots the items are written to, and those zeroed by the drop
+		c.cow(ring.NextPosition() + Position(len(items)-ring.Cap()) - 1)
+		if ring.zeroing {
+			c.cow(drop)
+		}
+	}
+	return c.buf.Roll(drop, items...)
 }
 
-type RingBuf[F any] struct {
-	drop Position
-	buf  []F
-	base Position
-	next int
+func (c *SyncBuf[F]) Append(item F) error {
+	_, err := c.AppendPos(item)
+	return err
 }
 
-func (b *RingBuf[F]) Drop(drop Position) error {
-	if b.next <= int(drop-b.base) { // b.base + b.next <= drop
-		return ErrOutOfRange
+// AppendPos appends item and returns its position.
+func (c *SyncBuf[F]) AppendPos(item F) (Position, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	if c.sealed {
+		return c.buf.NextPosition(), ErrSealed
 	}
-	b.drop = drop
-	return nil
+	c.beforeAppend()
+	return c.buf.AppendPos(item)
 }
 
-func (b *RingBuf[F]) Append(item F) error {
-	size := len(b.buf)
-	if size < int(b.base-b.drop)+b.next { // drop + len(buf) < b.base + b.next
-		return ErrBufferOverflow
+// AppendEvict appends item, evicting the oldest item first if the buffer is
+// full, and returns the evicted item.
+func (c *SyncBuf[F]) AppendEvict(item F) (evicted F, evictedPos Position, didEvict bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	if c.sealed {
+		return evicted, evictedPos, false
 	}
-	next := b.next % size
-	if next == 0 {
-		b.base += Position(size)
+	if ring, ok := c.buf.(*RingBuf[F]); ok {
+		c.cow(ring.NextPosition() - Position(ring.Cap()))
+		return ring.AppendEvict(item)
 	}
-	b.buf[next] = item
-	b.next = next + 1
-	return nil
+	if c.buf.Free() == 0 {
+		var err error
+		evicted, evictedPos, err = popFront(c.buf)
+		didEvict = err == nil
+	}
+	_ = c.buf.Append(item)
+	return evicted, evictedPos, didEvict
 }
 
-func (b *RingBuf[F]) Iterator(start Position) (*Iterator[F], error) {
-	head, tail, err := b.iter(start)
-	if err != nil {
-		return nil, err
+// AppendWait is like Append but blocks while the buffer is full until a Drop
+// makes room or ctx is done.
+func (c *SyncBuf[F]) AppendWait(ctx context.Context, item F) error {
+	for {
+		c.mu.Lock()
+		if c.sealed {
+			c.mu.Unlock()
+			return ErrSealed
+		}
+		c.beforeAppend()
+		err := c.buf.Append(item)
+		if !errors.Is(err, ErrBufferOverflow) {
+			c.notify()
+			c.mu.Unlock()
+			return err
+		}
+		wait := c.changed()
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
 	}
-	return NewIterator[F](head, tail), nil
 }
 
-func (b *RingBuf[F]) ToSlice(start Position) ([]F, error) {
-	head, tail, err := b.iter(start)
-	if err != nil {
-		return nil, err
-	}
-	return append(head, tail...), nil
+// AppendTimeout is AppendWait with a timeout of d, after which it returns
+// context.DeadlineExceeded.
+func (c *SyncBuf[F]) AppendTimeout(item F, d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return c.AppendWait(ctx, item)
 }
 
-func (b *RingBuf[F]) iter(start Position) ([]F, []F, error) {
-	if begin := start - b.base; 0 <= begin && begin <= Position(b.next) {
-		return b.buf[begin:b.next], nil, nil
-	}
-	if diff := int(b.base - start); 0 < diff && b.next+diff <= len(b.buf) {
-		begin := len(b.buf) - diff
-		return b.buf[begin:], b.buf[:b.next], nil
+// Seal makes the appends fail with ErrSealed from now on, including those
+// blocked in AppendWait, while the items can still be read and dropped.
+func (c *SyncBuf[F]) Seal() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	c.sealed = true
+	if s, ok := c.buf.(interface{ Seal() }); ok {
+		s.Seal()
 	}
-	bottom := b.base - Position(len(b.buf)-b.next)
-	upper := b.base + Position(b.next)
-	return nil, nil, fmt.Errorf("%w: %v not in range [%v, %v)",
-		ErrOutOfRange, start, bottom, upper)
 }
 
-func NewSyncBuf[F any](buf Buffer[F]) *SyncBuf[F] {
-	return &SyncBuf[F]{
-		mu:  sync.RWMutex{},
-		buf: buf,
+// Sealed reports whether Seal has been called.
+func (c *SyncBuf[F]) Sealed() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.sealed
+}
+
+// WithLock calls fn with the underlying buffer while holding the write lock,
+// so that several operations on it are atomic, and returns the error of fn.
+// fn must not retain the buffer nor call the methods of c.
+func (c *SyncBuf[F]) WithLock(fn func(buf Buffer[F]) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	c.cowAll() // fn may write any slot
+	return fn(c.buf)
+}
+
+func (c *SyncBuf[F]) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	c.cowAll()
+	c.buf.Reset()
+}
+
+func (c *SyncBuf[F]) ResetAt(start Position) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer c.notify()
+	c.cowAll()
+	c.buf.ResetAt(start)
+}
+
+// changed returns a channel closed on the next mutation. c.mu must be held
+// for writing.
+func (c *SyncBuf[F]) changed() <-chan struct{} {
+	if c.wait == nil {
+		c.wait = make(chan struct{})
 	}
+	return c.wait
 }
 
-type SyncBuf[F any] struct {
-	mu  sync.RWMutex
-	buf Buffer[F]
+// notify wakes up the goroutines waiting on changed and fires the channels
+// of Notify whose position has been appended. c.mu must be held for writing.
+func (c *SyncBuf[F]) notify() {
+	if c.wait != nil {
+		close(c.wait)
+		c.wait = nil
+	}
+	if len(c.watches) == 0 {
+		return
+	}
+	first, next := c.buf.FirstPosition(), c.buf.NextPosition()
+	watches := c.watches[:0]
+	for _, w := range c.watches {
+		if w.evicted && 0 < first-w.pos || !w.evicted && 0 < next-w.pos {
+			close(w.ch)
+		} else {
+			watches = append(watches, w)
+		}
+	}
+	for i := len(watches); i < len(c.watches); i++ {
+		c.watches[i] = watch{}
+	}
+	c.watches = watches
 }
 
-func (c *SyncBuf[F]) Drop(drop Position) error {
+// Notify returns a channel that is closed once the item at pos has been
+// appended. The channel stays registered until then. Reset and ResetAt close
+// it too if they move the next position past pos, although no item was
+// appended at pos, so that nobody waits for a position that will not be
+// appended anymore.
+func (c *SyncBuf[F]) Notify(pos Position) <-chan struct{} {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.buf.Drop(drop)
+	ch := make(chan struct{})
+	if 0 < c.buf.NextPosition()-pos { // pos < next
+		close(ch)
+		return ch
+	}
+	c.watches = append(c.watches, watch{pos: pos, ch: ch})
+	return ch
 }
 
-func (c *SyncBuf[F]) Append(item F) error {
+// NotifyEvicted returns a channel that is closed once the item at pos has
+// been dropped, e.g. evicted by an overwriting append, so that a consumer
+// that has not read it yet can resync from EarliestAvailable. Like Notify, it
+// is also closed by Reset and ResetAt moving the first position past pos.
+func (c *SyncBuf[F]) NotifyEvicted(pos Position) <-chan struct{} {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.buf.Append(item)
+	ch := make(chan struct{})
+	if 0 < c.buf.FirstPosition()-pos { // pos < first
+		close(ch)
+		return ch
+	}
+	c.watches = append(c.watches, watch{pos: pos, ch: ch, evicted: true})
+	return ch
+}
+
+// EarliestAvailable returns the position of the oldest item that has not
+// been dropped or evicted, where a consumer that has lost items resumes.
+func (c *SyncBuf[F]) EarliestAvailable() Position {
+	return c.FirstPosition()
 }
 
 func (c *SyncBuf[F]) ToSlice(start Position) ([]F, error) {
@@ -125,9 +1478,253 @@ func (c *SyncBuf[F]) ToSlice(start Position) ([]F, error) {
 	return ret, nil
 }
 
+func (c *SyncBuf[F]) ToSliceRange(start, end Position) ([]F, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	items, err := c.buf.ToSliceRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]F, len(items))
+	copy(ret, items)
+	return ret, nil
+}
+
+func (c *SyncBuf[F]) ToSliceN(start Position, max int) ([]F, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	items, err := c.buf.ToSliceN(start, max)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]F, len(items))
+	copy(ret, items)
+	return ret, nil
+}
+
+func (c *SyncBuf[F]) CopyTo(dst []F, start Position) (int, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.CopyTo(dst, start)
+}
+
+// AppendToSlice appends the items from start to dst and returns the extended
+// slice.
+func (c *SyncBuf[F]) AppendToSlice(dst []F, start Position) ([]F, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if ring, ok := c.buf.(*RingBuf[F]); ok {
+		return ring.AppendToSlice(dst, start)
+	}
+	if _, err := c.buf.CopyTo(nil, start); err != nil {
+		return dst, err
+	}
+	n := int(c.buf.NextPosition() - start)
+	if cap(dst)-len(dst) < n {
+		grown := make([]F, len(dst), len(dst)+n)
+		copy(grown, dst)
+		dst = grown
+	}
+	c.buf.CopyTo(dst[len(dst):len(dst)+n], start)
+	return dst[:len(dst)+n], nil
+}
+
+// Tail returns the newest n items, or all of them if there are fewer.
+func (c *SyncBuf[F]) Tail(n int) ([]F, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	items, err := c.buf.ToSlice(tailPosition(c.buf, n))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]F, len(items))
+	copy(ret, items)
+	return ret, nil
+}
+
+// ToSlicePooled is like ToSlice but copies the items into a slice taken from
+// a pool. Release of the returned PooledSlice puts the slice back.
+func (c *SyncBuf[F]) ToSlicePooled(start Position) (PooledSlice[F], error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, err := c.buf.CopyTo(nil, start); err != nil {
+		return PooledSlice[F]{}, err
+	}
+	n := int(c.buf.NextPosition() - start)
+	p, _ := c.pool.Get().(*[]F)
+	if p == nil || cap(*p) < n {
+		s := make([]F, n)
+		p = &s
+	}
+	*p = (*p)[:n]
+	c.buf.CopyTo(*p, start)
+	return PooledSlice[F]{Items: *p, p: p, pool: &c.pool}, nil
+}
+
+// PooledSlice holds the items copied by SyncBuf.ToSlicePooled.
+type PooledSlice[F any] struct {
+	Items []F
+	p     *[]F
+	pool  *sync.Pool
+}
+
+// Release clears Items and returns the slice to the pool of the SyncBuf. It
+// must be called at most once, and Items must not be used afterwards.
+func (s PooledSlice[F]) Release() {
+	if s.pool == nil {
+		return
+	}
+	var zero F
+	for i := range s.Items {
+		s.Items[i] = zero
+	}
+	*s.p = s.Items[:0]
+	s.pool.Put(s.p)
+}
+
 func (c *SyncBuf[F]) Iterator(start Position) (*Iterator[F], error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	return c.iterator(start)
+}
+
+// WaitFor blocks until the item at pos has been appended or ctx is done, and
+// returns an Iterator starting at pos.
+func (c *SyncBuf[F]) WaitFor(ctx context.Context, pos Position) (*Iterator[F], error) {
+	for {
+		c.mu.Lock()
+		if _, high := c.buf.Bounds(); pos-high < 0 { // pos < high
+			defer c.mu.Unlock()
+			return c.iterator(pos)
+		}
+		wait := c.changed()
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait:
+		}
+	}
+}
+
+// ToSliceWait returns the items from start, waiting up to d for the item at
+// start to be appended, after which it returns context.DeadlineExceeded.
+func (c *SyncBuf[F]) ToSliceWait(start Position, d time.Duration) ([]F, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	iter, err := c.WaitFor(ctx, start)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	return iter.ToSlice(), nil
+}
+
+// Subscribe streams the items from start onwards to the returned channel
+// until ctx is done. If the subscriber falls so far behind that items are
+// overwritten before being sent, onGap (if not nil) is called with the lost
+// range [from, to) and streaming resumes from to.
+func (c *SyncBuf[F]) Subscribe(ctx context.Context, start Position, onGap func(from, to Position)) (<-chan F, error) {
+	if low, high := c.Bounds(); start-low < 0 || 0 < start-high {
+		return nil, errOutOfRange(start, low, high)
+	}
+	ch := make(chan F)
+	go func() {
+		defer close(ch)
+		pos := start
+		for {
+			iter, err := c.WaitFor(ctx, pos)
+			if errors.Is(err, ErrOutOfRange) {
+				low, _ := c.Bounds()
+				if onGap != nil {
+					onGap(pos, low)
+				}
+				pos = low
+				continue
+			}
+			if err != nil {
+				return
+			}
+			for iter.Scan() {
+				select {
+				case <-ctx.Done():
+					iter.Close()
+					return
+				case ch <- iter.Item():
+					pos++
+				}
+			}
+			iter.Close()
+		}
+	}()
+	return ch, nil
+}
+
+// beforeAppend makes the slot an Append writes safe to write.
+func (c *SyncBuf[F]) beforeAppend() {
+	if ring, ok := c.buf.(*RingBuf[F]); ok && (ring.overwrite || 0 < ring.Free()) {
+		c.cow(ring.NextPosition() - Position(ring.Cap()))
+	}
+}
+
+// cow copies the backing array of the RingBuf if an iterator may read the
+// slot of pos. It requires the write lock.
+func (c *SyncBuf[F]) cow(pos Position) {
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	if c.snaps == 0 || pos-c.snapLow < 0 {
+		return
+	}
+	c.copyAliased()
+}
+
+// cowAll copies the backing array of the RingBuf if an iterator may read any
+// slot. It requires the write lock.
+func (c *SyncBuf[F]) cowAll() {
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	if c.snaps == 0 {
+		return
+	}
+	c.copyAliased()
+}
+
+// copyAliased detaches the open iterators from the backing array by copying
+// it. It requires the write lock and snapMu.
+func (c *SyncBuf[F]) copyAliased() {
+	ring := c.buf.(*RingBuf[F])
+	buf := make([]F, len(ring.buf))
+	copy(buf, ring.buf)
+	ring.buf = buf
+	c.snaps = 0
+	c.snapGen++
+}
+
+// iterator returns an iterator that is not affected by later mutations. For a
+// RingBuf it aliases the backing array instead of copying the items.
+func (c *SyncBuf[F]) iterator(start Position) (*Iterator[F], error) {
+	if ring, ok := c.buf.(*RingBuf[F]); ok {
+		head, tail, err := ring.iter(start)
+		if err != nil {
+			return nil, err
+		}
+		c.snapMu.Lock()
+		if c.snaps == 0 || start-c.snapLow < 0 {
+			c.snapLow = start
+		}
+		c.snaps++
+		gen := c.snapGen
+		c.snapMu.Unlock()
+		iter := NewIteratorAt[F](start, head, tail)
+		iter.release = func() {
+			c.snapMu.Lock()
+			defer c.snapMu.Unlock()
+			if gen == c.snapGen {
+				c.snaps--
+			}
+		}
+		return iter, nil
+	}
 	iter, err := c.buf.Iterator(start)
 	if err != nil {
 		return nil, err
@@ -137,40 +1734,290 @@ func (c *SyncBuf[F]) Iterator(start Position) (*Iterator[F], error) {
 		ss[i] = make([]F, len(base))
 		copy(ss[i], base)
 	}
-	return NewIterator[F](ss...), nil
+	return NewIteratorAt[F](iter.start, ss...), nil
+}
+
+func (c *SyncBuf[F]) Bounds() (Position, Position) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.Bounds()
+}
+
+func (c *SyncBuf[F]) String() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if s, ok := c.buf.(fmt.Stringer); ok {
+		return "SyncBuf{" + s.String() + "}"
+	}
+	return describe("SyncBuf", c.buf)
+}
+
+// Dump writes the internal layout of the underlying buffer to w if it has a
+// Dump method, and String otherwise.
+func (c *SyncBuf[F]) Dump(w io.Writer) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if d, ok := c.buf.(interface{ Dump(w io.Writer) error }); ok {
+		return d.Dump(w)
+	}
+	_, err := fmt.Fprintln(w, describe("SyncBuf", c.buf))
+	return err
+}
+
+// Contains reports whether pos is within Bounds.
+func (c *SyncBuf[F]) Contains(pos Position) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return contains(c.buf, pos)
+}
+
+func (c *SyncBuf[F]) FirstPosition() Position {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.FirstPosition()
+}
+
+func (c *SyncBuf[F]) NextPosition() Position {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.NextPosition()
+}
+
+func (c *SyncBuf[F]) Clone() Buffer[F] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.wrapClone(c.buf.Clone())
+}
+
+// wrapClone returns a SyncBuf decorating buf, a clone of c.buf, sealed if c
+// is. c.mu must be held.
+func (c *SyncBuf[F]) wrapClone(buf Buffer[F]) *SyncBuf[F] {
+	clone := NewSyncBuf[F](buf)
+	clone.sealed = c.sealed
+	return clone
+}
+
+// CloneFunc is like Clone but copies each retained item with copyItem. If the
+// decorated buffer has no CloneFunc method, the clone only holds the retained
+// items.
+func (c *SyncBuf[F]) CloneFunc(copyItem func(item F) F) Buffer[F] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if buf, ok := c.buf.(interface {
+		CloneFunc(copyItem func(item F) F) Buffer[F]
+	}); ok {
+		return c.wrapClone(buf.CloneFunc(copyItem))
+	}
+	first := c.buf.FirstPosition()
+	items, _ := c.buf.ToSlice(first)
+	clone := c.buf.Clone()
+	clone.ResetAt(first)
+	for _, item := range items {
+		_ = clone.Append(copyItem(item))
+	}
+	return c.wrapClone(clone)
+}
+
+func (c *SyncBuf[F]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.Len()
+}
+
+func (c *SyncBuf[F]) Cap() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.Cap()
+}
+
+func (c *SyncBuf[F]) MemoryUsage() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.MemoryUsage()
+}
+
+func (c *SyncBuf[F]) Free() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.Free()
+}
+
+func (c *SyncBuf[F]) IsEmpty() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.IsEmpty()
+}
+
+func (c *SyncBuf[F]) IsFull() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.IsFull()
+}
+
+func (c *SyncBuf[F]) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.Stats()
+}
+
+func (c *SyncBuf[F]) Get(pos Position) (F, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.buf.Get(pos)
 }
 
 func NewIterator[F any](slices ...[]F) *Iterator[F] {
+	return NewIteratorAt[F](0, slices...)
+}
+
+// NewIteratorAt returns an Iterator whose first item is at start.
+func NewIteratorAt[F any](start Position, slices ...[]F) *Iterator[F] {
 	return &Iterator[F]{
-		ss:   slices,
-		slot: 0,
-		idx:  -1,
+		ss:    slices,
+		slot:  0,
+		idx:   -1,
+		start: start,
+		off:   -1,
 	}
 }
 
 type Iterator[F any] struct {
-	ss   [][]F
-	slot int
-	idx  int
+	ss    [][]F
+	slot  int
+	idx   int
+	start Position
+	off   int
+	gen   *uint64 // generation of the aliased RingBuf, or nil
+	want  uint64
+	err   error
+
+	release func() // called once by Close
 }
 
 func (r *Iterator[F]) Scan() bool {
+	if r.gen != nil && *r.gen != r.want {
+		r.err = ErrConcurrentModification
+	}
+	if r.err != nil || r.slot >= len(r.ss) {
+		return false
+	}
 	r.idx++
 	if r.idx < len(r.ss[r.slot]) {
+		r.off++
 		return true
 	}
 	r.slot++
+	r.idx = -1 // before the first item of the new slot, which may be empty
 	if r.slot < len(r.ss) && len(r.ss[r.slot]) > 0 {
 		r.idx = 0
+		r.off++
 		return true
 	}
 	return false
 }
 
+// NextChunk returns the items up to the end of the current contiguous
+// segment with the position of the first one, and advances past them as
+// many calls to Scan would. A RingBuf yields at most two chunks. It returns
+// false once there are no more items. The chunk may alias the buffer and
+// must not be modified.
+func (r *Iterator[F]) NextChunk() ([]F, Position, bool) {
+	if r.gen != nil && *r.gen != r.want {
+		r.err = ErrConcurrentModification
+	}
+	for r.err == nil && r.slot < len(r.ss) {
+		s := r.ss[r.slot]
+		if rest := s[r.idx+1:]; 0 < len(rest) {
+			pos := r.start + Position(r.off+1)
+			r.idx = len(s) - 1
+			r.off += len(rest)
+			return rest[:len(rest):len(rest)], pos, true
+		}
+		r.slot++
+		r.idx = -1
+	}
+	return nil, 0, false
+}
+
+// Reset rewinds the iterator so that the next Scan yields the first item
+// again.
+func (r *Iterator[F]) Reset() {
+	r.seek(0)
+}
+
+// Close releases the iterator, after which Scan returns false. Closing the
+// iterators of a SyncBuf spares it copying the items they alias on the next
+// write; an iterator that is not closed is still safe to use. Close always
+// returns nil.
+func (r *Iterator[F]) Close() error {
+	if r.release != nil {
+		r.release()
+		r.release = nil
+	}
+	r.ss, r.slot = nil, 0
+	return nil
+}
+
+// Err returns ErrConcurrentModification if Scan stopped because the buffer
+// was modified, and nil otherwise.
+func (r *Iterator[F]) Err() error {
+	return r.err
+}
+
 func (r *Iterator[F]) Item() F {
 	return r.ss[r.slot][r.idx]
 }
 
+func (r *Iterator[F]) Position() Position {
+	return r.start + Position(r.off)
+}
+
+// Seek repositions the iterator so that the next Scan yields the item at pos.
+// Seeking to the position after the last item is allowed and ends the
+// iteration; any other position outside the items returns an OutOfRangeError
+// bounded by the positions of the items, like the other Readers.
+func (r *Iterator[F]) Seek(pos Position) error {
+	if !r.seek(int(pos - r.start)) {
+		return errOutOfRange(pos, r.start, r.start+Position(r.len()))
+	}
+	return nil
+}
+
+// Skip advances the iterator by up to n items and returns the number skipped.
+func (r *Iterator[F]) Skip(n int) int {
+	if rest := r.len() - (r.off + 1); rest < n {
+		n = rest
+	}
+	if n <= 0 {
+		return 0
+	}
+	r.seek(r.off + 1 + n)
+	return n
+}
+
+func (r *Iterator[F]) seek(off int) bool {
+	if off < 0 || r.len() < off {
+		return false
+	}
+	slot, idx := 0, off
+	for slot < len(r.ss)-1 && len(r.ss[slot]) <= idx {
+		idx -= len(r.ss[slot])
+		slot++
+	}
+	r.slot = slot
+	r.idx = idx - 1
+	r.off = off - 1
+	return true
+}
+
+func (r *Iterator[F]) len() int {
+	n := 0
+	for _, s := range r.ss {
+		n += len(s)
+	}
+	return n
+}
+
 func (r *Iterator[F]) ToSlice() []F {
 	var ret []F
 	for r.Scan() {
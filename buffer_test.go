@@ -1,8 +1,15 @@
 package ringbuf
 
 import (
+	"context"
 	"errors"
+	"io"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -20,7 +27,7 @@ func TestRingBuffer(t *testing.T) {
 	assert.NoError(t, buf.Append(item)) // 0
 	assert.NoError(t, buf.Append(item)) // 1
 	assert.NoError(t, buf.Append(item)) // 2
-	assert.Equal(t, ErrBufferOverflow, buf.Append(item))
+	assert.True(t, errors.Is(buf.Append(item), ErrBufferOverflow))
 
 	items, err = buf.ToSlice(0)
 	assert.NoError(t, err)
@@ -40,9 +47,9 @@ func TestRingBuffer(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 3, len(items))
 
-	assert.Equal(t, ErrBufferOverflow, buf.Append(item))
+	assert.True(t, errors.Is(buf.Append(item), ErrBufferOverflow))
 
-	assert.Equal(t, ErrOutOfRange, buf.Drop(6))
+	assert.True(t, errors.Is(buf.Drop(6), ErrOutOfRange))
 }
 
 func TestRingBufferIterate(t *testing.T) {
@@ -142,6 +149,66 @@ func TestRingBufferWrapAround(t *testing.T) {
 	checkAppendAndIterate(t, buf, large+4) // large+4, large+5, large+6
 }
 
+func TestNewRingBufAt(t *testing.T) {
+	var large Position = (1 << 31) - 1
+	var positions []Position
+	buf := NewRingBufAt[int](3, large, WithOnAppend[int](func(pos Position, item int) {
+		positions = append(positions, pos)
+	}))
+	assert.Equal(t, 0, buf.Len())
+	assert.Equal(t, large, buf.FirstPosition())
+	assert.Equal(t, large, buf.NextPosition())
+	_, err := buf.ToSlice(large + 1)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, []Position{large, large + 1, large + 2}, positions)
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+	items, err := buf.ToSlice(large + 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, items)
+	assert.NoError(t, buf.Drop(large))
+	assert.NoError(t, buf.Append(3))
+	assert.NoError(t, buf.Validate())
+}
+
+func TestComparePositions(t *testing.T) {
+	var large Position = (1 << 31) - 1
+	assert.Equal(t, -1, ComparePositions(1, 2))
+	assert.Equal(t, 0, ComparePositions(2, 2))
+	assert.Equal(t, 1, ComparePositions(2, 1))
+	assert.Equal(t, -1, ComparePositions(large, large+1))
+	assert.Equal(t, 1, ComparePositions(large+2, large-2))
+	assert.True(t, large+1 < large) // what a plain comparison gets wrong
+
+	assert.Equal(t, 3, PositionDistance(1, 4))
+	assert.Equal(t, -3, PositionDistance(4, 1))
+	assert.Equal(t, 2, PositionDistance(large, large+2))
+	assert.Equal(t, -4, PositionDistance(large+2, large-2))
+}
+
+func TestSerialNumbers(t *testing.T) {
+	var seq uint32 = 1<<32 - 2 // wraps to 0 after two items
+	buf := NewRingBuf[uint32](4)
+	buf.ResetAt(Position(seq))
+	for i := uint32(0); i < 4; i++ {
+		assert.NoError(t, buf.Append(seq+i))
+	}
+	items, err := buf.ToSlice(Position(seq + 1))
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{1<<32 - 1, 0, 1}, items)
+
+	assert.NoError(t, buf.Drop(Position(seq+2))) // drop up to 0
+	item, err := buf.Get(Position(uint32(1)))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), item)
+	_, err = buf.ToSlice(Position(seq + 5))
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.Equal(t, Position(2), buf.NextPosition())
+}
+
 func checkAppendAndIterate(t *testing.T, buf *RingBuf[Item], start Position) {
 	t.Helper()
 	{
@@ -178,3 +245,2284 @@ func checkAppendAndIterate(t *testing.T, buf *RingBuf[Item], start Position) {
 }
 
 type Item = any
+
+func TestRingBufferBounds(t *testing.T) {
+	buf := NewRingBuf[Item](3)
+	checkBounds(t, buf)
+
+	assert.NoError(t, buf.Append(nil))
+	assert.NoError(t, buf.Append(nil))
+	checkBounds(t, buf)
+
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(nil))
+	assert.NoError(t, buf.Append(nil))
+	low, high := buf.Bounds()
+	assert.Equal(t, Position(1), low)
+	assert.Equal(t, Position(4), high)
+	checkBounds(t, buf)
+
+	var large int32 = (1 << 31) - 1
+	checkBounds(t, &RingBuf[Item]{
+		buf:  make([]Item, 3),
+		drop: large - 3,
+		base: large,
+		next: 1,
+	})
+}
+
+func TestSyncBufBounds(t *testing.T) {
+	checkBounds(t, NewSyncBuf[Item](NewRingBuf[Item](3)))
+
+	buf := NewSyncBuf[Item](NewSliceBuf[Item](3))
+	assert.NoError(t, buf.Append(nil))
+	assert.NoError(t, buf.Append(nil))
+	assert.NoError(t, buf.Drop(0))
+	low, high := buf.Bounds()
+	assert.Equal(t, Position(1), low)
+	assert.Equal(t, Position(2), high)
+	checkBounds(t, buf)
+}
+
+func checkBounds(t *testing.T, buf Buffer[Item]) {
+	t.Helper()
+	low, high := buf.Bounds()
+	var err error
+
+	_, err = buf.ToSlice(low)
+	assert.NoError(t, err)
+	_, err = buf.ToSlice(high)
+	assert.NoError(t, err)
+
+	_, err = buf.ToSlice(low - 1)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = buf.ToSlice(high + 1)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func TestRingBufferClone(t *testing.T) {
+	buf := NewRingBuf[int](3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3)) // wrap around
+	assert.NoError(t, buf.Append(4))
+
+	clone := buf.Clone()
+	for _, start := range []Position{2, 3, 4, 5} {
+		expected, err := buf.ToSlice(start)
+		assert.NoError(t, err)
+		items, err := clone.ToSlice(start)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, items)
+	}
+
+	assert.NoError(t, buf.Drop(2))
+	assert.NoError(t, buf.Append(5))
+	assert.NoError(t, clone.Drop(3))
+	assert.NoError(t, clone.Append(50))
+	assert.NoError(t, clone.Append(60))
+
+	items, err := buf.ToSlice(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 4, 5}, items)
+	assert.True(t, errors.Is(buf.Append(6), ErrBufferOverflow))
+
+	items, err = clone.ToSlice(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 50, 60}, items)
+	_, err = clone.ToSlice(3)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func TestSyncBufClone(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](3))
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.Append(1))
+
+	clone := buf.Clone()
+	_, ok := clone.(*SyncBuf[int])
+	assert.True(t, ok)
+
+	assert.NoError(t, buf.Append(2))
+	assert.NoError(t, clone.Drop(0))
+	assert.NoError(t, clone.Append(20))
+
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, items)
+	items, err = clone.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 20}, items)
+}
+
+func TestRingBufferOverwrite(t *testing.T) {
+	buf := NewRingBuf[int](3, WithOverwrite[int]())
+	for i := 0; i < 8; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	items, err := buf.ToSlice(5)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5, 6, 7}, items)
+	_, err = buf.ToSlice(4)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.Equal(t, Position(4), buf.drop)
+
+	assert.NoError(t, buf.Drop(6))
+	assert.NoError(t, buf.Append(8))
+	assert.Equal(t, Position(6), buf.drop)
+	assert.NoError(t, buf.Append(9))
+	assert.NoError(t, buf.Append(10))
+	assert.Equal(t, Position(7), buf.drop)
+	items, err = buf.ToSlice(8)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{8, 9, 10}, items)
+}
+
+func TestRingBufferOverwriteEmpty(t *testing.T) {
+	buf := NewRingBuf[int](0, WithOverwrite[int]())
+	assert.True(t, errors.Is(buf.Append(1), ErrBufferOverflow))
+	_, err := buf.AppendAll([]int{1, 2})
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, 0, buf.Len())
+	assert.Equal(t, Position(0), buf.NextPosition())
+}
+
+func TestRingBufferLen(t *testing.T) {
+	buf := NewRingBuf[Item](3)
+	checkLen(t, buf, 0, 3)
+
+	assert.NoError(t, buf.Append(nil))
+	assert.NoError(t, buf.Append(nil))
+	checkLen(t, buf, 2, 3)
+	assert.NoError(t, buf.Append(nil))
+	checkLen(t, buf, 3, 3)
+
+	assert.NoError(t, buf.Drop(1))
+	checkLen(t, buf, 1, 3)
+	assert.NoError(t, buf.Append(nil))
+	checkLen(t, buf, 2, 3)
+
+	var large int32 = (1 << 31) - 1
+	checkLen(t, &RingBuf[Item]{
+		buf:  make([]Item, 3),
+		drop: large - 1,
+		base: large,
+		next: 2,
+	}, 2, 3)
+}
+
+func TestSyncBufLen(t *testing.T) {
+	buf := NewSyncBuf[Item](NewRingBuf[Item](3))
+	assert.NoError(t, buf.Append(nil))
+	checkLen(t, buf, 1, 3)
+
+	buf = NewSyncBuf[Item](NewSliceBuf[Item](3))
+	assert.NoError(t, buf.Append(nil))
+	assert.NoError(t, buf.Append(nil))
+	assert.NoError(t, buf.Drop(0))
+	checkLen(t, buf, 1, 3)
+}
+
+func checkLen(t *testing.T, buf Buffer[Item], length, capacity int) {
+	t.Helper()
+	assert.Equal(t, length, buf.Len())
+	assert.Equal(t, capacity, buf.Cap())
+	assert.Equal(t, capacity-length, buf.Free())
+}
+
+func TestRingBufferGet(t *testing.T) {
+	buf := NewRingBuf[int](3)
+	_, err := buf.Get(0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3)) // wrap around
+	assert.NoError(t, buf.Append(4))
+
+	checkGet(t, buf, 2, 5)
+}
+
+func TestSyncBufGet(t *testing.T) {
+	buf := NewSyncBuf[int](NewSliceBuf[int](3))
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(0))
+	checkGet(t, buf, 1, 3)
+}
+
+func checkGet(t *testing.T, buf Buffer[int], low, high Position) {
+	t.Helper()
+	for pos := low; pos < high; pos++ {
+		item, err := buf.Get(pos)
+		assert.NoError(t, err)
+		assert.Equal(t, int(pos), item)
+	}
+	_, err := buf.Get(low - 1)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = buf.Get(high)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func TestRingBufferSet(t *testing.T) {
+	buf := NewRingBuf[int](3)
+	assert.True(t, errors.Is(buf.Set(0, 0), ErrOutOfRange))
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3)) // wrap around
+
+	assert.NoError(t, buf.Set(1, 10))
+	assert.NoError(t, buf.Set(3, 30))
+	items, err := buf.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10, 2, 30}, items)
+
+	assert.True(t, errors.Is(buf.Set(0, 0), ErrOutOfRange))
+	assert.True(t, errors.Is(buf.Set(4, 0), ErrOutOfRange))
+}
+
+func TestRingBufferViews(t *testing.T) {
+	buf := NewRingBuf[int](3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(3)) // wrap around
+
+	head, tail, err := buf.Views(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, head)
+	assert.Equal(t, []int{3}, tail)
+
+	head, tail, err = buf.Views(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, head)
+	assert.Empty(t, tail)
+
+	head[0] = 30 // views alias the buffer
+	item, err := buf.Get(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 30, item)
+
+	_, _, err = buf.Views(5)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func TestRingBufferAppendAll(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	pos, err := buf.AppendAll([]int{0, 1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, Position(0), pos)
+
+	pos, err = buf.AppendAll([]int{3, 4})
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, Position(3), pos)
+	assert.Equal(t, 3, buf.Len())
+
+	assert.NoError(t, buf.Drop(1))
+	pos, err = buf.AppendAll([]int{3, 4, 5}) // wrap around
+	assert.NoError(t, err)
+	assert.Equal(t, Position(3), pos)
+
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4, 5}, items)
+
+	pos, err = buf.AppendAll(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Position(6), pos)
+}
+
+func TestRingBufferAppendAllOverwrite(t *testing.T) {
+	buf := NewRingBuf[int](4, WithOverwrite[int]())
+	pos, err := buf.AppendAll([]int{0, 1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, Position(0), pos)
+
+	pos, err = buf.AppendAll([]int{3, 4, 5})
+	assert.NoError(t, err)
+	assert.Equal(t, Position(3), pos)
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4, 5}, items)
+	assert.Equal(t, 4, buf.Len())
+
+	pos, err = buf.AppendAll([]int{6, 7, 8, 9, 10, 11, 12})
+	assert.NoError(t, err)
+	assert.Equal(t, Position(6), pos)
+	assert.Equal(t, 4, buf.Len())
+	low, high := buf.Bounds()
+	assert.Equal(t, Position(9), low)
+	assert.Equal(t, Position(13), high)
+	items, err = buf.ToSlice(9)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{9, 10, 11, 12}, items)
+
+	assert.NoError(t, buf.Append(13))
+	items, err = buf.ToSlice(10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10, 11, 12, 13}, items)
+}
+
+func TestRingBufferToSliceRange(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(4)) // wrap around
+	assert.NoError(t, buf.Append(5))
+
+	checkToSliceRange(t, buf)
+}
+
+func TestSyncBufToSliceRange(t *testing.T) {
+	checkToSliceRange(t, NewSyncBuf[int](NewRingBuf[int](4, WithOverwrite[int]())))
+
+	buf := NewSyncBuf[int](NewSliceBuf[int](4))
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	checkToSliceRange(t, buf)
+}
+
+func checkToSliceRange(t *testing.T, buf Buffer[int]) {
+	t.Helper()
+	if buf.Len() == 0 {
+		for i := 0; i < 6; i++ {
+			assert.NoError(t, buf.Append(i))
+		}
+	}
+	low, high := buf.Bounds()
+	for start := low; start <= high; start++ {
+		for end := start; end <= high; end++ {
+			items, err := buf.ToSliceRange(start, end)
+			assert.NoError(t, err)
+			assert.Equal(t, int(end-start), len(items))
+			for i, item := range items {
+				assert.Equal(t, int(start)+i, item)
+			}
+		}
+	}
+
+	var err error
+	_, err = buf.ToSliceRange(low-1, high)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = buf.ToSliceRange(low, high+1)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = buf.ToSliceRange(high, low)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func TestRingBufferCopyTo(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	checkCopyTo(t, buf)
+}
+
+func TestSyncBufCopyTo(t *testing.T) {
+	checkCopyTo(t, NewSyncBuf[int](NewRingBuf[int](4)))
+	checkCopyTo(t, NewSyncBuf[int](NewSliceBuf[int](4)))
+}
+
+func checkCopyTo(t *testing.T, buf Buffer[int]) {
+	t.Helper()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(4))
+	assert.NoError(t, buf.Append(5))
+
+	dst := make([]int, 8)
+	n, err := buf.CopyTo(dst, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, []int{2, 3, 4, 5}, dst[:n])
+
+	n, err = buf.CopyTo(dst[:3], 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int{3, 4, 5}, dst[:n])
+
+	n, err = buf.CopyTo(dst[:2], 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int{2, 3}, dst[:n])
+
+	_, err = buf.CopyTo(dst, 7)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func TestRingBufferReset(t *testing.T) {
+	buf := NewRingBuf[int](3)
+	checkReset(t, buf)
+
+	buf.Reset()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3))
+	buf.Reset()
+	items, err := buf.ToSlice(-3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 0}, items)
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestSyncBufReset(t *testing.T) {
+	checkReset(t, NewSyncBuf[int](NewRingBuf[int](3)))
+	checkReset(t, NewSyncBuf[int](NewSliceBuf[int](3)))
+}
+
+func checkReset(t *testing.T, buf Buffer[int]) {
+	t.Helper()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+
+	buf.ResetAt(100)
+	assert.Equal(t, 0, buf.Len())
+	_, high := buf.Bounds()
+	assert.Equal(t, Position(100), high)
+	for i := 100; i < 103; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.True(t, errors.Is(buf.Append(103), ErrBufferOverflow))
+	items, err := buf.ToSlice(100)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{100, 101, 102}, items)
+
+	buf.Reset()
+	assert.Equal(t, 0, buf.Len())
+	items, err = buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+	assert.NoError(t, buf.Append(0))
+	item, err := buf.Get(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, item)
+}
+
+func TestRingBufferPositions(t *testing.T) {
+	checkPositions(t, NewRingBuf[Item](3))
+
+	buf := NewRingBuf[Item](3, WithOverwrite[Item]())
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, buf.Append(nil))
+	}
+	assert.Equal(t, Position(2), buf.FirstPosition())
+	assert.Equal(t, Position(5), buf.NextPosition())
+}
+
+func TestSyncBufPositions(t *testing.T) {
+	checkPositions(t, NewSyncBuf[Item](NewRingBuf[Item](3)))
+	checkPositions(t, NewSyncBuf[Item](NewSliceBuf[Item](3)))
+}
+
+func checkPositions(t *testing.T, buf Buffer[Item]) {
+	t.Helper()
+	assert.Equal(t, Position(0), buf.FirstPosition())
+	assert.Equal(t, Position(0), buf.NextPosition())
+
+	assert.NoError(t, buf.Append(nil))
+	assert.NoError(t, buf.Append(nil))
+	assert.Equal(t, Position(0), buf.FirstPosition())
+	assert.Equal(t, Position(2), buf.NextPosition())
+
+	assert.NoError(t, buf.Drop(0))
+	assert.Equal(t, Position(1), buf.FirstPosition())
+	assert.Equal(t, Position(2), buf.NextPosition())
+
+	assert.NoError(t, buf.Append(nil))
+	assert.NoError(t, buf.Append(nil))
+	assert.Error(t, buf.Append(nil))
+	assert.Equal(t, Position(1), buf.FirstPosition())
+	assert.Equal(t, Position(4), buf.NextPosition())
+
+	buf.ResetAt(10)
+	assert.Equal(t, Position(10), buf.FirstPosition())
+	assert.Equal(t, Position(10), buf.NextPosition())
+}
+
+func TestRingBufferTruncatedSequence(t *testing.T) {
+	var seq uint64 = 1<<40 - 2 // crosses a 1<<32 boundary
+	buf := NewRingBuf[uint64](3)
+	buf.ResetAt(Position(seq))
+	for i := uint64(0); i < 6; i++ {
+		if i >= 3 {
+			assert.NoError(t, buf.Drop(Position(seq+i-3)))
+		}
+		assert.NoError(t, buf.Append(seq+i))
+	}
+	items, err := buf.ToSlice(Position(seq + 3))
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{seq + 3, seq + 4, seq + 5}, items)
+	assert.Equal(t, Position(seq+6), buf.NextPosition())
+}
+
+func TestRingBufferIteratorPosition(t *testing.T) {
+	checkIteratorPosition(t, NewRingBuf[int](4))
+}
+
+func TestSyncBufIteratorPosition(t *testing.T) {
+	checkIteratorPosition(t, NewSyncBuf[int](NewRingBuf[int](4)))
+	checkIteratorPosition(t, NewSyncBuf[int](NewSliceBuf[int](4)))
+}
+
+func checkIteratorPosition(t *testing.T, buf Buffer[int]) {
+	t.Helper()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(4))
+	assert.NoError(t, buf.Append(5))
+
+	iter, err := buf.Iterator(2)
+	assert.NoError(t, err)
+	var positions []Position
+	for iter.Scan() {
+		assert.Equal(t, int(iter.Position()), iter.Item())
+		positions = append(positions, iter.Position())
+	}
+	assert.Equal(t, []Position{2, 3, 4, 5}, positions)
+}
+
+func TestIteratorSeek(t *testing.T) {
+	iter := NewIteratorAt[int](10, []int{10, 11}, []int{12, 13, 14})
+
+	assert.NoError(t, iter.Seek(12))
+	assert.True(t, iter.Scan())
+	assert.Equal(t, Position(12), iter.Position())
+	assert.Equal(t, 12, iter.Item())
+
+	assert.NoError(t, iter.Seek(11))
+	assert.Equal(t, []int{11, 12, 13, 14}, iter.ToSlice())
+
+	assert.NoError(t, iter.Seek(10))
+	assert.True(t, iter.Scan())
+	assert.Equal(t, 10, iter.Item())
+
+	assert.NoError(t, iter.Seek(15))
+	assert.False(t, iter.Scan())
+	assert.False(t, iter.Scan())
+
+	assert.True(t, errors.Is(iter.Seek(9), ErrOutOfRange))
+	assert.True(t, errors.Is(iter.Seek(16), ErrOutOfRange))
+	var outOfRange OutOfRangeError
+	assert.True(t, errors.As(iter.Seek(16), &outOfRange))
+	assert.Equal(t, OutOfRangeError{Requested: 16, Low: 10, High: 15}, outOfRange)
+}
+
+func TestIteratorSkip(t *testing.T) {
+	iter := NewIteratorAt[int](10, []int{10, 11}, []int{12, 13, 14})
+
+	assert.Equal(t, 1, iter.Skip(1))
+	assert.True(t, iter.Scan())
+	assert.Equal(t, 11, iter.Item())
+
+	assert.Equal(t, 2, iter.Skip(2))
+	assert.True(t, iter.Scan())
+	assert.Equal(t, Position(14), iter.Position())
+
+	iter = NewIteratorAt[int](10, []int{10, 11}, []int{12, 13, 14})
+	assert.Equal(t, 0, iter.Skip(0))
+	assert.Equal(t, 5, iter.Skip(10))
+	assert.False(t, iter.Scan())
+	assert.Equal(t, 0, iter.Skip(1))
+}
+
+func TestIteratorReset(t *testing.T) {
+	iter := NewIteratorAt[int](10, []int{10, 11}, []int{12})
+	assert.Equal(t, []int{10, 11, 12}, iter.ToSlice())
+	iter.Reset()
+	assert.True(t, iter.Scan())
+	assert.Equal(t, Position(10), iter.Position())
+	assert.Equal(t, []int{11, 12}, iter.ToSlice())
+
+	assert.NoError(t, iter.Close())
+	assert.False(t, iter.Scan())
+	iter.Reset()
+	assert.False(t, iter.Scan())
+	assert.NoError(t, iter.Close())
+}
+
+func TestIteratorNextChunk(t *testing.T) {
+	b := NewRingBuf[int](4)
+	_, err := b.AppendAll([]int{0, 1, 2, 3})
+	assert.NoError(t, err)
+	assert.NoError(t, b.Drop(1))
+	_, err = b.AppendAll([]int{4, 5})
+	assert.NoError(t, err)
+	iter, err := b.Iterator(2)
+	assert.NoError(t, err)
+	var got [][]int
+	n := 0
+	for {
+		chunk, pos, ok := iter.NextChunk()
+		if !ok {
+			break
+		}
+		assert.Equal(t, Position(2+n), pos)
+		got = append(got, chunk)
+		n += len(chunk)
+	}
+	assert.Equal(t, [][]int{{2, 3}, {4, 5}}, got)
+	assert.False(t, iter.Scan())
+	assert.NoError(t, iter.Close())
+
+	iter = NewIteratorAt[int](10, []int{10, 11}, nil, []int{12, 13})
+	assert.True(t, iter.Scan())
+	chunk, pos, ok := iter.NextChunk()
+	assert.True(t, ok)
+	assert.Equal(t, Position(11), pos)
+	assert.Equal(t, []int{11}, chunk)
+	assert.Equal(t, Position(11), iter.Position())
+	chunk, pos, ok = iter.NextChunk()
+	assert.True(t, ok)
+	assert.Equal(t, Position(12), pos)
+	assert.Equal(t, []int{12, 13}, chunk)
+	_, _, ok = iter.NextChunk()
+	assert.False(t, ok)
+	iter.Reset()
+	assert.True(t, iter.Scan())
+	assert.True(t, iter.Scan())
+	assert.Equal(t, 11, iter.Item())
+	assert.NoError(t, iter.Close())
+
+	iter, err = b.Iterator(2)
+	assert.NoError(t, err)
+	assert.NoError(t, b.Drop(2))
+	_, _, ok = iter.NextChunk()
+	assert.False(t, ok)
+	assert.ErrorIs(t, iter.Err(), ErrConcurrentModification)
+
+	// NextChunk after Scan exhausted an unwrapped buffer, whose tail is empty
+	c := NewRingBuf[int](4)
+	_, err = c.AppendAll([]int{0, 1, 2})
+	assert.NoError(t, err)
+	iter, err = c.Iterator(0)
+	assert.NoError(t, err)
+	for iter.Scan() {
+	}
+	_, _, ok = iter.NextChunk()
+	assert.False(t, ok)
+	assert.False(t, iter.Scan())
+	assert.NoError(t, iter.Err())
+}
+
+func TestRingBufferToSliceNoAlias(t *testing.T) {
+	b := NewRingBuf[int](4)
+	_, err := b.AppendAll([]int{0, 1, 2})
+	assert.NoError(t, err)
+	items, err := b.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cap(items))
+	items[0] = -1
+	assert.NoError(t, b.Append(3))
+	got, err := b.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3}, got)
+	assert.Equal(t, []int{-1, 1, 2}, items)
+
+	assert.NoError(t, b.Drop(1))
+	assert.NoError(t, b.Append(4))
+	for _, f := range []func() ([]int, error){
+		func() ([]int, error) { return b.ToSlice(2) },
+		func() ([]int, error) { return b.ToSliceRange(2, 5) },
+		func() ([]int, error) { return b.ToSliceN(2, 3) },
+	} {
+		items, err := f()
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 3, 4}, items)
+		assert.Equal(t, 3, cap(items))
+		items[0] = -1
+	}
+	got, err = b.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, got)
+}
+
+func TestRingBufferToSliceN(t *testing.T) {
+	checkToSliceN(t, NewRingBuf[int](4))
+}
+
+// extendedBuf is the part of RingBuf beyond Buffer that SyncBuf also
+// provides, whatever it wraps.
+type extendedBuf interface {
+	Buffer[int]
+	AppendToSlice(dst []int, start Position) ([]int, error)
+	DropN(n int) error
+	DropAll() error
+	DropWhile(fn func(pos Position, item int) bool) int
+	Tail(n int) ([]int, error)
+	PopFront() (int, Position, error)
+	Oldest() (int, Position, error)
+	Newest() (int, Position, error)
+	Seal()
+	Sealed() bool
+	CloneFunc(copyItem func(item int) int) Buffer[int]
+}
+
+// extendedBufs returns a RingBuf of size items with opts, and SyncBufs
+// wrapping another such RingBuf and an SPSCBuf.
+func extendedBufs(size int, opts ...Option[int]) []extendedBuf {
+	return []extendedBuf{
+		NewRingBuf[int](size, opts...),
+		NewSyncBuf[int](NewRingBuf[int](size, opts...)),
+		NewSyncBuf[int](NewSPSCBuf[int](size)),
+	}
+}
+
+func TestAppendToSlice(t *testing.T) {
+	for _, buf := range extendedBufs(4) {
+		for i := 0; i < 6; i++ {
+			if i >= 4 {
+				assert.NoError(t, buf.Drop(Position(i-4)))
+			}
+			assert.NoError(t, buf.Append(i))
+		}
+		dst := make([]int, 1, 8)
+		dst, err := buf.AppendToSlice(dst, 4)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 4, 5}, dst)
+		dst, err = buf.AppendToSlice(dst, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 4, 5, 2, 3, 4, 5}, dst)
+		dst, err = buf.AppendToSlice(dst, 6)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, len(dst))
+		dst, err = buf.AppendToSlice(dst[:0], 3)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3, 4, 5}, dst)
+
+		dst, err = buf.AppendToSlice(dst, 7)
+		assert.True(t, errors.Is(err, ErrOutOfRange))
+		assert.Equal(t, []int{3, 4, 5}, dst)
+	}
+}
+
+func TestDropErrors(t *testing.T) {
+	b := NewRingBuf[int](4)
+	_, err := b.AppendAll([]int{0, 1, 2})
+	assert.NoError(t, err)
+	assert.NoError(t, b.Drop(0))
+
+	err = b.Drop(3)
+	assert.ErrorIs(t, err, ErrOutOfRange)
+	assert.Equal(t, OutOfRangeError{Requested: 3, Low: 1, High: 3}, err)
+	assert.Equal(t, Position(1), b.FirstPosition())
+
+	err = b.Drop(-1)
+	assert.ErrorIs(t, err, ErrDropRegression)
+	assert.NotErrorIs(t, err, ErrOutOfRange)
+	assert.Equal(t, Position(1), b.FirstPosition())
+	assert.Equal(t, 2, b.Len())
+
+	assert.NoError(t, b.Drop(0))
+	assert.NoError(t, b.Drop(2))
+	assert.Equal(t, 0, b.Len())
+	assert.Equal(t, uint64(1), b.Stats().OutOfRanges)
+}
+
+func TestDropIfHigher(t *testing.T) {
+	b := NewRingBuf[int](4)
+	_, err := b.AppendAll([]int{0, 1, 2, 3})
+	assert.NoError(t, err)
+	for _, ack := range []Position{1, 0, 2, 1, -1} {
+		assert.NoError(t, b.DropIfHigher(ack))
+	}
+	assert.Equal(t, Position(3), b.FirstPosition())
+	assert.Equal(t, 1, b.Len())
+	assert.ErrorIs(t, b.DropIfHigher(4), ErrOutOfRange)
+
+	c := NewSyncBuf[int](NewRingBuf[int](64))
+	for i := 0; i < 64; i++ {
+		assert.NoError(t, c.Append(i))
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < 64; i += 4 {
+				assert.NoError(t, c.DropIfHigher(Position(i)))
+				runtime.Gosched()
+			}
+		}(w)
+	}
+	wg.Wait()
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, uint64(64), c.Stats().Drops)
+}
+
+func TestDropN(t *testing.T) {
+	for _, buf := range extendedBufs(4) {
+		assert.NoError(t, buf.DropAll())
+		assert.NoError(t, buf.DropN(0))
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, buf.Append(i))
+		}
+		assert.NoError(t, buf.DropN(2))
+		assert.Equal(t, Position(2), buf.FirstPosition())
+		assert.True(t, errors.Is(buf.DropN(3), ErrOutOfRange))
+		assert.NoError(t, buf.DropN(-1))
+		assert.Equal(t, 2, buf.Len())
+
+		assert.NoError(t, buf.DropAll())
+		assert.Equal(t, 0, buf.Len())
+		assert.Equal(t, Position(4), buf.FirstPosition())
+		assert.NoError(t, buf.DropAll())
+		assert.Equal(t, Position(4), buf.FirstPosition())
+	}
+}
+
+func TestTail(t *testing.T) {
+	for _, buf := range extendedBufs(4) {
+		items, err := buf.Tail(2)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(items))
+		for i := 0; i < 6; i++ {
+			if buf.Free() == 0 {
+				assert.NoError(t, buf.Drop(Position(i-4)))
+			}
+			assert.NoError(t, buf.Append(i))
+		}
+		assert.NoError(t, buf.Drop(2))
+		items, err = buf.Tail(2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{4, 5}, items)
+		items, err = buf.Tail(5)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3, 4, 5}, items)
+		items, err = buf.Tail(-1)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(items))
+	}
+}
+
+func TestDropWhile(t *testing.T) {
+	for _, buf := range extendedBufs(4) {
+		assert.Equal(t, 0, buf.DropWhile(func(Position, int) bool { return true }))
+		for i := 0; i < 4; i++ {
+			assert.NoError(t, buf.Append(i*10))
+		}
+		var positions []Position
+		assert.Equal(t, 2, buf.DropWhile(func(pos Position, item int) bool {
+			positions = append(positions, pos)
+			return item < 20
+		}))
+		assert.Equal(t, []Position{0, 1, 2}, positions)
+		assert.Equal(t, Position(2), buf.FirstPosition())
+		assert.Equal(t, 0, buf.DropWhile(func(Position, int) bool { return false }))
+		assert.Equal(t, 2, buf.DropWhile(func(Position, int) bool { return true }))
+		assert.Equal(t, 0, buf.Len())
+	}
+}
+
+func TestPopFront(t *testing.T) {
+	for _, buf := range extendedBufs(2, WithZeroing[int]()) {
+		_, pos, err := buf.PopFront()
+		assert.True(t, errors.Is(err, ErrBufferUnderflow))
+		assert.Equal(t, Position(0), pos)
+
+		for i := 0; i < 4; i++ {
+			if i >= 2 {
+				item, pos, err := buf.PopFront()
+				assert.NoError(t, err)
+				assert.Equal(t, i-2, item)
+				assert.Equal(t, Position(i-2), pos)
+			}
+			assert.NoError(t, buf.Append(i))
+		}
+		item, pos, err := buf.PopFront()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, item)
+		assert.Equal(t, Position(2), pos)
+		assert.Equal(t, 1, buf.Len())
+		assert.Equal(t, Position(3), buf.FirstPosition())
+	}
+}
+
+func TestOldestNewest(t *testing.T) {
+	for _, buf := range append(extendedBufs(2, WithOverwrite[int]()), NewSyncBuf[int](NewMPMCBuf[int](2))) {
+		_, pos, err := buf.Oldest()
+		assert.True(t, errors.Is(err, ErrBufferUnderflow))
+		assert.Equal(t, Position(0), pos)
+		_, pos, err = buf.Newest()
+		assert.True(t, errors.Is(err, ErrBufferUnderflow))
+		assert.Equal(t, Position(-1), pos)
+
+		oldest := []int{0, 0, 1, 2}
+		for i := 0; i < 4; i++ {
+			if buf.Free() == 0 {
+				assert.NoError(t, buf.Drop(Position(i-2)))
+			}
+			assert.NoError(t, buf.Append(i))
+			item, pos, err := buf.Oldest()
+			assert.NoError(t, err)
+			assert.Equal(t, oldest[i], item)
+			assert.Equal(t, Position(item), pos)
+			item, pos, err = buf.Newest()
+			assert.NoError(t, err)
+			assert.Equal(t, i, item)
+			assert.Equal(t, Position(i), pos)
+		}
+	}
+}
+
+func TestContains(t *testing.T) {
+	ring := NewRingBuf[int](3)
+	buf := NewSyncBuf[int](NewSPSCBuf[int](3))
+	for _, buf := range []Buffer[int]{ring, buf} {
+		for i := 0; i < 5; i++ {
+			if buf.Free() == 0 {
+				assert.NoError(t, buf.Drop(Position(i-3)))
+			}
+			assert.NoError(t, buf.Append(i))
+		}
+	}
+	assert.NoError(t, ring.Drop(3))
+	assert.False(t, ring.Contains(1))
+	assert.True(t, ring.Contains(2)) // dropped but not overwritten
+	assert.True(t, ring.Contains(4))
+	assert.False(t, ring.Contains(5))
+
+	assert.False(t, buf.Contains(1))
+	assert.True(t, buf.Contains(2))
+	assert.True(t, buf.Contains(4))
+	assert.False(t, buf.Contains(5))
+}
+
+func TestRingBufIteratorModification(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	iter, err := buf.Iterator(0)
+	assert.NoError(t, err)
+	assert.True(t, iter.Scan())
+	assert.Equal(t, 0, iter.Item())
+	assert.NoError(t, buf.Append(3))
+	assert.False(t, iter.Scan())
+	assert.True(t, errors.Is(iter.Err(), ErrConcurrentModification))
+	assert.False(t, iter.Scan())
+
+	mutations := []func(){
+		func() { assert.NoError(t, buf.Drop(0)) },
+		func() { assert.NoError(t, buf.Set(3, 4)) },
+		func() { assert.NoError(t, buf.InsertAt(3, 5)) },
+		func() { buf.ResetAt(4) },
+	}
+	for _, mutate := range mutations {
+		iter, err := buf.Iterator(buf.FirstPosition())
+		assert.NoError(t, err)
+		mutate()
+		assert.False(t, iter.Scan())
+		assert.True(t, errors.Is(iter.Err(), ErrConcurrentModification))
+	}
+
+	assert.NoError(t, buf.Append(4))
+	iter, err = buf.Iterator(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4}, iter.ToSlice())
+	assert.NoError(t, iter.Err())
+
+	synced := NewSyncBuf[int](buf)
+	iter, err = synced.Iterator(4)
+	assert.NoError(t, err)
+	assert.NoError(t, synced.Append(5))
+	assert.Equal(t, []int{4}, iter.ToSlice())
+	assert.NoError(t, iter.Err())
+}
+
+func TestRingBufDump(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	for i := 0; i < 6; i++ {
+		if buf.Free() == 0 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(2))
+	assert.Equal(t, "RingBuf{first: 3, next: 6, bounds: [2, 6), len: 3, cap: 4}", buf.String())
+
+	w := &strings.Builder{}
+	assert.NoError(t, buf.Dump(w))
+	assert.Equal(t, `drop 2, base 4, next 2, mask 0, size 4
+slot 0: pos 4 retained
+slot 1: pos 5 retained
+slot 2: pos 2 dropped <- next append
+slot 3: pos 3 retained
+`, w.String())
+
+	synced := NewSyncBuf[int](buf)
+	assert.Equal(t, "SyncBuf{"+buf.String()+"}", synced.String())
+	w.Reset()
+	assert.NoError(t, synced.Dump(w))
+	assert.Contains(t, w.String(), "slot 3: pos 3 retained")
+
+	spsc := NewSyncBuf[int](NewSPSCBuf[int](2))
+	assert.Equal(t, "SyncBuf{first: 0, next: 0, bounds: [0, 0), len: 0, cap: 2}", spsc.String())
+	w.Reset()
+	assert.NoError(t, spsc.Dump(w))
+	assert.Equal(t, spsc.String()+"\n", w.String())
+}
+
+func TestSyncBufPopFrontConcurrent(t *testing.T) {
+	const consumers, n = 4, 1000
+	buf := NewSyncBuf[int](NewRingBuf[int](n))
+	for i := 0; i < n; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	received := make([][]int, consumers)
+	wg := sync.WaitGroup{}
+	for k := 0; k < consumers; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			for {
+				item, _, err := buf.PopFront()
+				if errors.Is(err, ErrBufferUnderflow) {
+					return
+				}
+				received[k] = append(received[k], item)
+				runtime.Gosched()
+			}
+		}(k)
+	}
+	wg.Wait()
+	var all []int
+	for _, items := range received {
+		all = append(all, items...)
+	}
+	sort.Ints(all)
+	assert.Equal(t, n, len(all))
+	for i, item := range all {
+		if !assert.Equal(t, i, item) {
+			break
+		}
+	}
+}
+
+func TestSyncBufToSlicePooled(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](4))
+	for i := 0; i < 6; i++ {
+		if i >= 4 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+	}
+	pooled, err := buf.ToSlicePooled(2)
+	assert.NoError(t, err)
+	items := pooled.Items
+	assert.Equal(t, []int{2, 3, 4, 5}, items)
+	pooled.Release()
+	assert.Equal(t, []int{0, 0, 0, 0}, items)
+
+	pooled, err = buf.ToSlicePooled(5)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5}, pooled.Items)
+	pooled.Release()
+
+	pooled, err = buf.ToSlicePooled(7)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.Nil(t, pooled.Items)
+	pooled.Release()
+}
+
+func TestSyncBufToSliceN(t *testing.T) {
+	checkToSliceN(t, NewSyncBuf[int](NewRingBuf[int](4)))
+	checkToSliceN(t, NewSyncBuf[int](NewSliceBuf[int](4)))
+}
+
+func checkToSliceN(t *testing.T, buf Buffer[int]) {
+	t.Helper()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(4))
+	assert.NoError(t, buf.Append(5))
+
+	cases := []struct {
+		start    Position
+		max      int
+		expected []int
+	}{
+		{start: 2, max: 0, expected: []int{}},
+		{start: 2, max: 1, expected: []int{2}},
+		{start: 2, max: 3, expected: []int{2, 3, 4}},
+		{start: 2, max: 4, expected: []int{2, 3, 4, 5}},
+		{start: 2, max: 10, expected: []int{2, 3, 4, 5}},
+		{start: 4, max: 1, expected: []int{4}},
+		{start: 6, max: 1, expected: []int{}},
+	}
+	for _, c := range cases {
+		items, err := buf.ToSliceN(c.start, c.max)
+		assert.NoError(t, err)
+		assert.Equal(t, c.expected, append([]int{}, items...))
+	}
+
+	_, err := buf.ToSliceN(7, 1)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func TestSyncBufAppendWait(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](2))
+	ctx := context.Background()
+	assert.NoError(t, buf.AppendWait(ctx, 0))
+	assert.NoError(t, buf.AppendWait(ctx, 1))
+
+	done := make(chan error)
+	go func() {
+		done <- buf.AppendWait(ctx, 2)
+	}()
+	select {
+	case <-done:
+		t.Fatal("AppendWait returned before Drop")
+	case <-time.After(10 * time.Millisecond):
+	}
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, <-done)
+
+	items, err := buf.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, items)
+}
+
+func TestSyncBufAppendWaitCancel(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](1))
+	assert.NoError(t, buf.Append(0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, buf.AppendWait(ctx, 1))
+	assert.Equal(t, 1, buf.Len())
+}
+
+func TestSyncBufTimeout(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](1))
+	assert.NoError(t, buf.AppendTimeout(0, time.Millisecond))
+	assert.Equal(t, context.DeadlineExceeded, buf.AppendTimeout(1, 10*time.Millisecond))
+	assert.Equal(t, 1, buf.Len())
+
+	items, err := buf.ToSliceWait(0, time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0}, items)
+	_, err = buf.ToSliceWait(1, 10*time.Millisecond)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	done := make(chan []int)
+	go func() {
+		items, err := buf.ToSliceWait(1, time.Minute)
+		assert.NoError(t, err)
+		done <- items
+	}()
+	go func() {
+		assert.NoError(t, buf.AppendTimeout(1, time.Minute))
+	}()
+	runtime.Gosched()
+	assert.NoError(t, buf.Drop(0))
+	assert.Equal(t, []int{1}, <-done)
+}
+
+func TestSyncBufWaitFor(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](3))
+	ctx := context.Background()
+	assert.NoError(t, buf.Append(0))
+
+	iter, err := buf.WaitFor(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0}, iter.ToSlice())
+
+	done := make(chan *Iterator[int])
+	go func() {
+		iter, err := buf.WaitFor(ctx, 2)
+		assert.NoError(t, err)
+		done <- iter
+	}()
+	assert.NoError(t, buf.Append(1))
+	select {
+	case <-done:
+		t.Fatal("WaitFor returned before the position was appended")
+	case <-time.After(10 * time.Millisecond):
+	}
+	assert.NoError(t, buf.Append(2))
+	iter = <-done
+	assert.True(t, iter.Scan())
+	assert.Equal(t, Position(2), iter.Position())
+	assert.Equal(t, 2, iter.Item())
+
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3))
+	_, err = buf.WaitFor(ctx, 0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = buf.WaitFor(ctx, 4)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestSyncBufNotify(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](3))
+	assert.NoError(t, buf.Append(0))
+	fired := func(ch <-chan struct{}) bool {
+		select {
+		case <-ch:
+			return true
+		default:
+			return false
+		}
+	}
+	assert.True(t, fired(buf.Notify(0)))
+	assert.True(t, fired(buf.Notify(-1)))
+
+	one, two := buf.Notify(1), buf.Notify(2)
+	assert.False(t, fired(one))
+	assert.NoError(t, buf.Append(1))
+	assert.True(t, fired(one))
+	assert.False(t, fired(two))
+	assert.NoError(t, buf.AppendWait(context.Background(), 2))
+	assert.True(t, fired(two))
+	assert.Equal(t, 0, len(buf.watches))
+
+	// a reset past the position fires the channel without appending it
+	five := buf.Notify(5)
+	buf.ResetAt(4)
+	assert.False(t, fired(five))
+	buf.ResetAt(6)
+	assert.True(t, fired(five))
+	assert.Equal(t, 0, buf.Len())
+
+	seven := buf.Notify(7)
+	done := make(chan struct{})
+	go func() {
+		<-seven
+		close(done)
+	}()
+	assert.NoError(t, buf.Append(6))
+	assert.NoError(t, buf.Append(7))
+	<-done
+}
+
+func TestSyncBufSubscribe(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](3))
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.NoError(t, buf.Append(0))
+
+	ch, err := buf.Subscribe(ctx, 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, <-ch)
+	for i := 1; i < 10; i++ {
+		if i >= 3 {
+			assert.NoError(t, buf.Drop(Position(i-3)))
+		}
+		assert.NoError(t, buf.Append(i))
+		assert.Equal(t, i, <-ch)
+	}
+
+	cancel()
+	for range ch {
+	}
+
+	_, err = buf.Subscribe(context.Background(), 0, nil)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = buf.Subscribe(context.Background(), 11, nil)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func TestSyncBufSubscribeGap(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](3, WithOverwrite[int]()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	type gap struct{ from, to Position }
+	gaps := make(chan gap, 1)
+	ch, err := buf.Subscribe(ctx, 0, func(from, to Position) {
+		gaps <- gap{from: from, to: to}
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, buf.Append(0))
+	assert.Equal(t, 0, <-ch)
+	for i := 1; i < 8; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	var received []int
+	for len(received) == 0 || received[len(received)-1] != 7 {
+		received = append(received, <-ch)
+	}
+	assert.Equal(t, []int{5, 6, 7}, received[len(received)-3:])
+	g := <-gaps
+	assert.Equal(t, Position(5), g.to)
+	last := 0
+	if len(received) > 3 {
+		last = received[len(received)-4]
+	}
+	assert.Equal(t, Position(last+1), g.from)
+}
+
+type hookRecord struct {
+	pos  Position
+	item int
+}
+
+func TestRingBufferHooks(t *testing.T) {
+	var appended, dropped, evicted []hookRecord
+	buf := NewRingBuf[int](3,
+		WithOverwrite[int](),
+		WithOnAppend(func(pos Position, item int) { appended = append(appended, hookRecord{pos, item}) }),
+		WithOnDrop(func(pos Position, item int) { dropped = append(dropped, hookRecord{pos, item}) }),
+		WithOnEvict(func(pos Position, item int) { evicted = append(evicted, hookRecord{pos, item}) }),
+	)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, []hookRecord{{0, 0}, {1, 1}, {2, 2}}, appended)
+
+	assert.NoError(t, buf.Drop(1))
+	assert.Equal(t, []hookRecord{{0, 0}, {1, 1}}, dropped)
+	assert.NoError(t, buf.Drop(1))
+	assert.Equal(t, 2, len(dropped))
+
+	assert.NoError(t, buf.Append(3))
+	assert.NoError(t, buf.Append(4))
+	assert.Empty(t, evicted)
+	assert.NoError(t, buf.Append(5))
+	assert.Equal(t, []hookRecord{{2, 2}}, evicted)
+	assert.Equal(t, hookRecord{5, 5}, appended[len(appended)-1])
+
+	appended, evicted = nil, nil
+	_, err := buf.AppendAll([]int{6, 7})
+	assert.NoError(t, err)
+	assert.Equal(t, []hookRecord{{6, 6}, {7, 7}}, appended)
+	assert.Equal(t, []hookRecord{{3, 3}, {4, 4}}, evicted)
+
+	appended, evicted = nil, nil
+	_, err = buf.AppendAll([]int{8, 9, 10, 11})
+	assert.NoError(t, err)
+	assert.Equal(t, []hookRecord{{8, 8}, {9, 9}, {10, 10}, {11, 11}}, appended)
+	assert.Equal(t, []hookRecord{{5, 5}, {6, 6}, {7, 7}, {8, 8}}, evicted)
+
+	dropped = nil
+	buf.Reset()
+	assert.Equal(t, []hookRecord{{9, 9}, {10, 10}, {11, 11}}, dropped)
+}
+
+func TestRingBufferEvictBatch(t *testing.T) {
+	var evicted []int
+	var starts []Position
+	buf := NewRingBuf[int](4,
+		WithOverwrite[int](),
+		WithOnEvictBatch(func(start Position, items []int) {
+			starts = append(starts, start)
+			evicted = append(evicted, items...)
+		}),
+	)
+	_, err := buf.AppendAll([]int{0, 1, 2, 3, 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []Position{0}, starts)
+	assert.Equal(t, []int{0}, evicted)
+
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(5))
+	assert.Equal(t, []int{0}, evicted)
+	assert.NoError(t, buf.Append(6))
+	assert.Equal(t, []int{0, 2}, evicted)
+
+	starts, evicted = nil, nil
+	_, err = buf.AppendAll([]int{7, 8, 9}) // evicts 3, 4 and 5 across the wrap point
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 4, 5}, evicted)
+	assert.Equal(t, Position(3), starts[0])
+
+	starts, evicted = nil, nil
+	_, err = buf.AppendAll([]int{10, 11, 12, 13, 14, 15})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{6, 7, 8, 9, 10, 11}, evicted)
+	assert.Equal(t, Position(6), starts[0])
+	assert.Equal(t, Position(10), starts[len(starts)-1])
+}
+
+func TestRingBufferResize(t *testing.T) {
+	buf := NewRingBuf[int](3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(3)) // wrap around
+
+	assert.True(t, errors.Is(buf.Resize(2), ErrBufferOverflow))
+	assert.True(t, errors.Is(buf.Resize(0), ErrBufferOverflow))
+
+	assert.NoError(t, buf.Resize(5))
+	assert.Equal(t, 5, buf.Cap())
+	assert.NoError(t, buf.Append(4))
+	assert.NoError(t, buf.Append(5))
+	assert.True(t, errors.Is(buf.Append(6), ErrBufferOverflow))
+	items, err := buf.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, items)
+
+	assert.NoError(t, buf.Drop(3))
+	assert.NoError(t, buf.Resize(2))
+	items, err = buf.ToSlice(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 5}, items)
+	assert.Equal(t, Position(4), buf.FirstPosition())
+	assert.Equal(t, Position(6), buf.NextPosition())
+	assert.True(t, errors.Is(buf.Append(6), ErrBufferOverflow))
+}
+
+func TestRingBufferPow2(t *testing.T) {
+	buf := NewRingBufPow2[int](3)
+	assert.Equal(t, 4, buf.Cap())
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.True(t, errors.Is(buf.Append(4), ErrBufferOverflow))
+	for i := 4; i < 11; i++ {
+		assert.NoError(t, buf.Drop(Position(i-4)))
+		assert.NoError(t, buf.Append(i))
+	}
+	items, err := buf.ToSlice(7)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{7, 8, 9, 10}, items)
+
+	assert.NoError(t, buf.Drop(8))
+	_, err = buf.AppendAll([]int{11, 12})
+	assert.NoError(t, err)
+	items, err = buf.ToSlice(9)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{9, 10, 11, 12}, items)
+
+	assert.Equal(t, 1, NewRingBufPow2[int](1).Cap())
+	assert.Equal(t, 1024, NewRingBufPow2[int](1024).Cap())
+	assert.Equal(t, 2048, NewRingBufPow2[int](1025).Cap())
+
+	assert.NoError(t, buf.Resize(6))
+	assert.Equal(t, 0, buf.mask)
+	assert.NoError(t, buf.Resize(8))
+	assert.Equal(t, 0, buf.mask)
+}
+
+func TestRingBufferPow2AroundZero(t *testing.T) {
+	buf := NewRingBufPow2[Item](3)
+	buf.ResetAt(-6)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(nil))
+	}
+	for start := Position(-5); start < 3; start++ {
+		assert.NoError(t, buf.Drop(start-1))
+		assert.NoError(t, buf.Append(nil))
+		items, err := buf.ToSlice(start)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, len(items))
+	}
+}
+
+func TestRingBufferPow2Slot(t *testing.T) {
+	pow2 := NewRingBufPow2[int](4)
+	ring := NewRingBuf[int](4)
+	for _, buf := range []*RingBuf[int]{pow2, ring} {
+		buf.ResetAt(-3)
+		for i := -3; i < 6; i++ {
+			if buf.IsFull() {
+				assert.NoError(t, buf.Drop(buf.FirstPosition()))
+			}
+			assert.NoError(t, buf.Append(i))
+		}
+		assert.NoError(t, buf.Drop(2))
+	}
+	for pos := Position(-4); pos < 8; pos++ {
+		i, ok := pow2.slot(pos)
+		j, ok2 := ring.slot(pos)
+		assert.Equal(t, ok2, ok, "pos %v", pos)
+		assert.Equal(t, j, i, "pos %v", pos)
+
+		item, err := pow2.Get(pos)
+		want, wantErr := ring.Get(pos)
+		assert.Equal(t, want, item)
+		assert.Equal(t, wantErr, err)
+	}
+}
+
+func TestRingBufferZeroing(t *testing.T) {
+	buf := NewRingBuf[*int](3, WithZeroing[*int]())
+	for i := 0; i < 3; i++ {
+		i := i
+		assert.NoError(t, buf.Append(&i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Nil(t, items[0])
+	assert.Nil(t, items[1])
+	assert.Equal(t, 2, *items[2])
+
+	clone := buf.Clone().(*RingBuf[*int])
+	assert.NoError(t, clone.Drop(2))
+	item, err := clone.Get(2)
+	assert.NoError(t, err)
+	assert.Nil(t, item)
+
+	kept := NewRingBuf[*int](3)
+	assert.NoError(t, kept.Append(new(int)))
+	assert.NoError(t, kept.Drop(0))
+	item, err = kept.Get(0)
+	assert.NoError(t, err)
+	assert.NotNil(t, item)
+}
+
+// checkStats expects an empty buffer of capacity 3.
+func checkStats(t *testing.T, buf Buffer[int]) {
+	t.Helper()
+	assert.Equal(t, Stats{}, buf.Stats())
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Drop(0)) // no items released
+	_, err := buf.Get(10)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.True(t, errors.Is(buf.Drop(10), ErrOutOfRange))
+	assert.Equal(t, Stats{
+		Appends:     3,
+		Drops:       1,
+		Overflows:   1,
+		OutOfRanges: 2,
+		Len:         2,
+		PeakLen:     3,
+	}, buf.Stats())
+
+	buf.Reset()
+	assert.Equal(t, Stats{
+		Appends:     3,
+		Drops:       3,
+		Overflows:   1,
+		OutOfRanges: 2,
+		Len:         0,
+		PeakLen:     3,
+	}, buf.Stats())
+	assert.Equal(t, buf.Stats(), buf.Clone().Stats())
+}
+
+// checkAppendPos checks AppendPos of a buffer of capacity 3.
+func checkAppendPos(t *testing.T, buf Buffer[int]) {
+	t.Helper()
+	buf.ResetAt(10)
+	for i := 0; i < 3; i++ {
+		pos, err := buf.AppendPos(i)
+		assert.NoError(t, err)
+		assert.Equal(t, Position(10+i), pos)
+	}
+	pos, err := buf.AppendPos(3)
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, Position(13), pos)
+	assert.NoError(t, buf.Drop(10))
+	pos, err = buf.AppendPos(3)
+	assert.NoError(t, err)
+	assert.Equal(t, Position(13), pos)
+	item, err := buf.Get(13)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, item)
+}
+
+func TestRingBufferAppendPos(t *testing.T) {
+	checkAppendPos(t, NewRingBuf[int](3))
+	checkAppendPos(t, NewSyncBuf[int](NewRingBuf[int](3)))
+	checkAppendPos(t, NewSliceBuf[int](3))
+	checkAppendPos(t, NewGrowableRingBuf[int](2, 3))
+	checkAppendPos(t, NewTimedBuf[int](3))
+	checkAppendPos(t, NewAggregateBuf[int, int](NewRingBuf[int](3), func(item int) int { return item }))
+	checkAppendPos(t, NewDurableBuf[int](NewRingBuf[int](3), io.Discard, intCodec{}))
+
+	buf := NewRingBuf[int](2, WithOverwrite[int]())
+	for i := 0; i < 4; i++ {
+		pos, err := buf.AppendPos(i)
+		assert.NoError(t, err)
+		assert.Equal(t, Position(i), pos)
+	}
+}
+
+func TestAppendEvict(t *testing.T) {
+	var evictions []Position
+	onEvict := WithOnEvict[int](func(pos Position, item int) {
+		evictions = append(evictions, pos)
+	})
+	cases := []struct {
+		buf interface {
+			Buffer[int]
+			AppendEvict(item int) (int, Position, bool)
+		}
+		evictions []Position
+	}{
+		{buf: NewRingBuf[int](2, onEvict), evictions: []Position{1, 2}},
+		{buf: NewRingBuf[int](2, onEvict, WithOverwrite[int]()), evictions: []Position{1, 2}},
+		{buf: NewSyncBuf[int](NewRingBuf[int](2, onEvict)), evictions: []Position{1, 2}},
+		{buf: NewSyncBuf[int](NewSPSCBuf[int](2))},
+	}
+	for _, c := range cases {
+		buf := c.buf
+		evictions = nil
+		for i := 0; i < 2; i++ {
+			_, _, ok := buf.AppendEvict(i)
+			assert.False(t, ok)
+		}
+		assert.NoError(t, buf.Drop(0))
+		_, _, ok := buf.AppendEvict(2)
+		assert.False(t, ok)
+		for i := 3; i < 5; i++ {
+			item, pos, ok := buf.AppendEvict(i)
+			assert.True(t, ok)
+			assert.Equal(t, i-2, item)
+			assert.Equal(t, Position(i-2), pos)
+		}
+		items, err := buf.ToSlice(3)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3, 4}, items)
+		assert.Equal(t, c.evictions, evictions)
+	}
+
+	empty := NewRingBuf[int](0)
+	_, _, ok := empty.AppendEvict(0)
+	assert.False(t, ok)
+	assert.Equal(t, 0, empty.Len())
+}
+
+func TestSeal(t *testing.T) {
+	for _, buf := range extendedBufs(3) {
+		assert.NoError(t, buf.Append(0))
+		assert.NoError(t, buf.Append(1))
+		assert.False(t, buf.Sealed())
+		buf.Seal()
+		assert.True(t, buf.Sealed())
+
+		pos, err := buf.AppendPos(2)
+		assert.True(t, errors.Is(err, ErrSealed))
+		assert.Equal(t, Position(2), pos)
+		assert.True(t, errors.Is(buf.Append(2), ErrSealed))
+		assert.Equal(t, 2, buf.Len())
+
+		items, err := buf.ToSlice(0)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{0, 1}, items)
+		assert.NoError(t, buf.Drop(0))
+		assert.Equal(t, 1, buf.Len())
+
+		for _, clone := range []Buffer[int]{buf.Clone(), buf.CloneFunc(func(item int) int { return item })} {
+			assert.True(t, errors.Is(clone.Append(2), ErrSealed))
+			assert.Equal(t, 1, clone.Len())
+		}
+	}
+
+	ring := NewRingBuf[int](3)
+	assert.NoError(t, ring.Append(0))
+	assert.NoError(t, ring.Append(1))
+	ring.Seal()
+	_, err := ring.AppendAll([]int{2})
+	assert.True(t, errors.Is(err, ErrSealed))
+	assert.True(t, errors.Is(ring.InsertAt(2, 2), ErrSealed))
+	_, _, ok := ring.AppendEvict(2)
+	assert.False(t, ok)
+	ring.Reset()
+	assert.True(t, errors.Is(ring.Append(0), ErrSealed))
+
+	synced := NewSyncBuf[int](NewRingBuf[int](1))
+	assert.NoError(t, synced.Append(0))
+	done := make(chan error)
+	go func() {
+		done <- synced.AppendWait(context.Background(), 1)
+	}()
+	runtime.Gosched()
+	synced.Seal()
+	assert.True(t, errors.Is(<-done, ErrSealed))
+}
+
+func TestRoll(t *testing.T) {
+	for _, buf := range []Buffer[int]{
+		NewRingBuf[int](3),
+		NewSyncBuf[int](NewRingBuf[int](3, WithZeroing[int]())),
+		NewSyncBuf[int](NewSPSCBuf[int](3)),
+		NewSliceBuf[int](3),
+		NewSPSCBuf[int](3),
+		NewMPMCBuf[int](4),
+		NewShardedBuf[int](3, 1),
+		NewSnapshotBuf[int](3),
+		NewSegmentedBuf[int](3, 2),
+		NewGrowableRingBuf[int](3, 3),
+		NewAckBuf[int](NewRingBuf[int](3)),
+		NewAggregateBuf[int, int](NewRingBuf[int](3), func(item int) int { return item }),
+		NewWeightedBuf[int](NewRingBuf[int](3), 100, func(item int) int { return item }, false),
+	} {
+		assert.NoError(t, buf.Roll(-1, 0, 1, 2))
+		assert.NoError(t, buf.Roll(1, 3, 4))
+		items, err := buf.ToSlice(buf.FirstPosition())
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 3, 4}, items)
+		assert.True(t, errors.Is(buf.Roll(5), ErrOutOfRange))
+		// neither drops nor appends anything if the items do not fit
+		assert.True(t, errors.Is(buf.Roll(2, 5, 6, 7, 8), ErrBufferOverflow))
+		items, err = buf.ToSlice(buf.FirstPosition())
+		assert.NoError(t, err)
+		assert.Equal(t, []int{2, 3, 4}, items)
+	}
+
+	ring := NewRingBuf[int](3)
+	assert.NoError(t, ring.Roll(-1, 0, 1, 2))
+	assert.True(t, errors.Is(ring.Roll(0, 3, 4), ErrBufferOverflow))
+	items, err := ring.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, items)
+	assert.True(t, errors.Is(ring.Roll(-2, 3), ErrDropRegression))
+	ring.Seal()
+	assert.True(t, errors.Is(ring.Roll(1), ErrSealed))
+	assert.Equal(t, 3, ring.Len())
+
+	// an overwriting buffer evicts the items that do not fit
+	for _, buf := range []Buffer[int]{
+		NewRingBuf[int](3, WithOverwrite[int]()),
+		NewAckBuf[int](NewRingBuf[int](3, WithOverwrite[int]())),
+		NewWeightedBuf[int](NewTraced[int](NewRingBuf[int](3, WithOverwrite[int]()), &recordingTracer{}), 100, func(item int) int { return item }, true),
+		NewWeightedBuf[int](NewInstrumented[int]("", NewRingBuf[int](3, WithOverwrite[int]())), 100, func(item int) int { return item }, true),
+	} {
+		assert.NoError(t, buf.Roll(-1, 0, 1, 2))
+		assert.NoError(t, buf.Roll(0, 3, 4, 5))
+		items, err = buf.ToSlice(buf.FirstPosition())
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3, 4, 5}, items)
+	}
+
+	growable := NewGrowableRingBuf[int](2, 4)
+	assert.NoError(t, growable.Roll(-1, 0, 1, 2, 3))
+	assert.True(t, errors.Is(growable.Roll(0, 4, 5), ErrBufferOverflow))
+	assert.Equal(t, Position(0), growable.FirstPosition())
+	assert.NoError(t, growable.Roll(1, 4, 5))
+	assert.Equal(t, 4, growable.Len())
+}
+
+func TestSyncBufRoll(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](3))
+	assert.NoError(t, buf.Roll(-1, 0, 1, 2))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 3; i < 100; i++ {
+			assert.NoError(t, buf.Roll(Position(i-3), i))
+			runtime.Gosched()
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		assert.Equal(t, 3, buf.Len())
+		runtime.Gosched()
+	}
+}
+
+func TestSyncBufWithLock(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](3))
+	assert.NoError(t, buf.Append(0))
+	iter, err := buf.Iterator(0)
+	assert.NoError(t, err)
+
+	appendBatch := func(items ...int) error {
+		return buf.WithLock(func(b Buffer[int]) error {
+			if b.Free() < len(items) {
+				return ErrBufferOverflow
+			}
+			for _, item := range items {
+				if err := b.Append(item); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	assert.NoError(t, appendBatch(1, 2))
+	assert.True(t, errors.Is(appendBatch(3), ErrBufferOverflow))
+	assert.NoError(t, buf.WithLock(func(b Buffer[int]) error {
+		if err := b.Drop(1); err != nil {
+			return err
+		}
+		return b.Append(3)
+	}))
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, items)
+	assert.Equal(t, []int{0}, iter.ToSlice()) // not affected by fn
+
+	ch := buf.Notify(4)
+	assert.NoError(t, buf.WithLock(func(b Buffer[int]) error {
+		return b.Append(4)
+	}))
+	<-ch
+}
+
+func TestMemoryUsage(t *testing.T) {
+	cases := []struct {
+		buf  Buffer[int64]
+		want int64
+	}{
+		{buf: NewRingBuf[int64](4), want: 32},
+		{buf: NewSyncBuf[int64](NewRingBuf[int64](4)), want: 32},
+		{buf: NewSliceBuf[int64](4), want: 64}, // twice the size, see NewSliceBuf
+		{buf: NewSPSCBuf[int64](4), want: 32},
+		{buf: NewMPMCBuf[int64](4), want: 64},
+		{buf: NewShardedBuf[int64](2, 2), want: 32},
+		{buf: NewGrowableRingBuf[int64](4, 8), want: 32},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, c.buf.MemoryUsage())
+	}
+
+	snapshot := NewSnapshotBuf[int64](4)
+	assert.Equal(t, int64(0), snapshot.MemoryUsage())
+	assert.NoError(t, snapshot.Append(0))
+	assert.Equal(t, int64(64), snapshot.MemoryUsage())
+
+	segmented := NewSegmentedBuf[int64](4, 2)
+	empty := segmented.MemoryUsage()
+	assert.NoError(t, segmented.Append(0))
+	assert.Equal(t, empty+16, segmented.MemoryUsage())
+	assert.NoError(t, segmented.Append(1))
+	assert.NoError(t, segmented.Drop(1))
+	assert.Equal(t, empty, segmented.MemoryUsage())
+
+	packets := NewRingBuf[[]byte](4)
+	assert.NoError(t, packets.Append(make([]byte, 100)))
+	assert.NoError(t, packets.Append(make([]byte, 50)))
+	size := func(item []byte) int { return cap(item) }
+	assert.Equal(t, packets.MemoryUsage()+150, EstimateMemory[[]byte](packets, size))
+}
+
+func TestIsEmptyIsFull(t *testing.T) {
+	bufs := []Buffer[int]{
+		NewRingBuf[int](2),
+		NewSyncBuf[int](NewRingBuf[int](2)),
+		NewSliceBuf[int](2),
+		NewSPSCBuf[int](2),
+		NewMPMCBuf[int](2),
+		NewShardedBuf[int](2, 1),
+		NewSnapshotBuf[int](2),
+		NewSegmentedBuf[int](2, 1),
+	}
+	for _, buf := range bufs {
+		r := ReadOnly[int](buf)
+		assert.True(t, buf.IsEmpty())
+		assert.False(t, buf.IsFull())
+		assert.NoError(t, buf.Append(0))
+		assert.False(t, buf.IsEmpty())
+		assert.False(t, buf.IsFull())
+		assert.NoError(t, buf.Append(1))
+		assert.True(t, buf.IsFull())
+		assert.True(t, r.IsFull())
+		assert.NoError(t, buf.Drop(0))
+		assert.False(t, buf.IsFull())
+		assert.NoError(t, buf.Drop(1))
+		assert.True(t, buf.IsEmpty())
+		assert.True(t, r.IsEmpty())
+	}
+
+	ring := NewRingBuf[int](2, WithOverwrite[int]())
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, ring.Append(i))
+	}
+	assert.True(t, ring.IsFull())
+	v, err := View[int](ring, 1, 2)
+	assert.NoError(t, err)
+	assert.True(t, v.IsFull())
+	assert.False(t, v.IsEmpty())
+}
+
+func TestSyncBufNotifyEvicted(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](2, WithOverwrite[int]()))
+	assert.NoError(t, buf.Append(0))
+	evicted := buf.NotifyEvicted(0)
+	later := buf.NotifyEvicted(2)
+	assert.NoError(t, buf.Append(1))
+	select {
+	case <-evicted:
+		t.Fatal("closed before the item was evicted")
+	default:
+	}
+	assert.NoError(t, buf.Append(2)) // evicts 0
+	<-evicted
+	assert.Equal(t, Position(1), buf.EarliestAvailable())
+
+	assert.NoError(t, buf.Drop(2))
+	<-later
+	assert.Equal(t, Position(3), buf.EarliestAvailable())
+	<-buf.NotifyEvicted(1)
+
+	appended := buf.Notify(3)
+	assert.NoError(t, buf.Append(3))
+	<-appended
+
+	reset := buf.NotifyEvicted(4)
+	buf.ResetAt(10)
+	<-reset
+}
+
+func TestBufferCapabilities(t *testing.T) {
+	produce := func(w Appender[int], n int) {
+		for i := 0; i < n; i++ {
+			assert.NoError(t, w.Append(i))
+		}
+	}
+	consume := func(r Reader[int], d Dropper) []int {
+		items, err := r.ToSlice(r.FirstPosition())
+		assert.NoError(t, err)
+		assert.NoError(t, d.Drop(r.NextPosition()-1))
+		return items
+	}
+	for _, buf := range []Buffer[int]{NewRingBuf[int](3), NewSPSCBuf[int](3), NewSyncBuf[int](NewSliceBuf[int](3))} {
+		produce(buf, 3)
+		assert.Equal(t, []int{0, 1, 2}, consume(buf, buf))
+		assert.Equal(t, 0, buf.Len())
+	}
+}
+
+func TestRingBufferStats(t *testing.T) {
+	checkStats(t, NewRingBuf[int](3))
+	checkStats(t, NewSyncBuf[int](NewRingBuf[int](3)))
+	checkStats(t, NewSliceBuf[int](3))
+
+	buf := NewRingBuf[int](3, WithOverwrite[int]())
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	_, err := buf.AppendAll([]int{5, 6, 7, 8})
+	assert.NoError(t, err)
+	stats := buf.Stats()
+	assert.Equal(t, uint64(9), stats.Appends)
+	assert.Equal(t, uint64(6), stats.Drops)
+	assert.Equal(t, 3, stats.PeakLen)
+}
+
+func TestRingBufferInsertAt(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.InsertAt(3, 3))
+	assert.Equal(t, Position(4), buf.NextPosition())
+	assert.True(t, buf.Has(0))
+	assert.False(t, buf.Has(1))
+	assert.False(t, buf.Has(2))
+	assert.True(t, buf.Has(3))
+	assert.False(t, buf.Has(4))
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 0, 3}, items)
+
+	assert.True(t, errors.Is(buf.InsertAt(4, 4), ErrBufferOverflow))
+	assert.NoError(t, buf.InsertAt(1, 1))
+	assert.True(t, buf.Has(1))
+	assert.NoError(t, buf.Drop(1))
+	assert.True(t, errors.Is(buf.InsertAt(1, 1), ErrOutOfRange))
+	assert.False(t, buf.Has(1))
+
+	assert.NoError(t, buf.InsertAt(5, 5)) // wrap around
+	clone := buf.Clone().(*RingBuf[int])
+	assert.NoError(t, buf.Resize(8))
+	for _, b := range []*RingBuf[int]{buf, clone} {
+		assert.False(t, b.Has(2))
+		assert.True(t, b.Has(3))
+		assert.False(t, b.Has(4))
+		assert.True(t, b.Has(5))
+	}
+
+	assert.NoError(t, buf.InsertAt(2, 2))
+	assert.NoError(t, buf.Append(6))
+	assert.NoError(t, buf.InsertAt(4, 4))
+	for pos := Position(2); pos < 7; pos++ {
+		assert.True(t, buf.Has(pos))
+	}
+	items, err = buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4, 5, 6}, items)
+
+	buf.Reset()
+	assert.NoError(t, buf.Append(0))
+	assert.True(t, buf.Has(0))
+}
+
+func TestRingBufferMissing(t *testing.T) {
+	buf := NewRingBuf[int](8)
+	assert.Empty(t, buf.Missing())
+	buf.ResetAt(-3)
+	assert.NoError(t, buf.Append(-3))
+	assert.NoError(t, buf.InsertAt(0, 0))
+	assert.NoError(t, buf.InsertAt(3, 3))
+	assert.Equal(t, []PositionRange{{-2, 0}, {1, 3}}, buf.Missing())
+
+	assert.NoError(t, buf.InsertAt(2, 2))
+	assert.NoError(t, buf.Drop(-2))
+	assert.Equal(t, []PositionRange{{-1, 0}, {1, 2}}, buf.Missing())
+
+	assert.NoError(t, buf.InsertAt(-1, -1))
+	assert.NoError(t, buf.InsertAt(1, 1))
+	assert.Empty(t, buf.Missing())
+}
+
+func TestRingBufferFindPosition(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	assert.Equal(t, Position(0), buf.FindPosition(func(int) bool { return true }))
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i*10))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(40)) // wrap around
+	assert.NoError(t, buf.Append(50))
+
+	for _, tc := range []struct {
+		min  int
+		want Position
+	}{
+		{min: 0, want: 2},
+		{min: 20, want: 2},
+		{min: 25, want: 3},
+		{min: 40, want: 4},
+		{min: 50, want: 5},
+		{min: 60, want: 6},
+	} {
+		got := buf.FindPosition(func(item int) bool { return tc.min <= item })
+		assert.Equal(t, tc.want, got, tc.min)
+	}
+}
+
+func TestRingBufferCloneFunc(t *testing.T) {
+	copyItem := func(item []int) []int { return append([]int(nil), item...) }
+	for _, buf := range []Buffer[[]int]{
+		NewRingBuf[[]int](3),
+		NewSyncBuf[[]int](NewRingBuf[[]int](3)),
+		NewSyncBuf[[]int](NewSPSCBuf[[]int](3)),
+	} {
+		buf.ResetAt(5)
+		assert.NoError(t, buf.Append([]int{1}))
+		assert.NoError(t, buf.Append([]int{2}))
+		assert.NoError(t, buf.Drop(5))
+		var clone Buffer[[]int]
+		switch b := buf.(type) {
+		case *RingBuf[[]int]:
+			clone = b.CloneFunc(copyItem)
+		case *SyncBuf[[]int]:
+			clone = b.CloneFunc(copyItem)
+		}
+		item, err := buf.Get(6)
+		assert.NoError(t, err)
+		item[0] = 20
+
+		assert.Equal(t, Position(6), clone.FirstPosition())
+		items, err := clone.ToSlice(6)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]int{{2}}, items)
+	}
+}
+
+func TestRingBufferValidate(t *testing.T) {
+	buf := NewRingBufPow2[int](4)
+	assert.NoError(t, buf.Validate())
+	for i := 0; i < 6; i++ {
+		if buf.Free() == 0 {
+			assert.NoError(t, buf.Drop(buf.FirstPosition()))
+		}
+		assert.NoError(t, buf.Append(i))
+		assert.NoError(t, buf.Validate())
+	}
+
+	broken := buf.Clone().(*RingBuf[int])
+	broken.next = 0
+	assert.True(t, errors.Is(broken.Validate(), ErrInvalidState))
+	broken = buf.Clone().(*RingBuf[int])
+	broken.drop = broken.base + Position(broken.next)
+	assert.True(t, errors.Is(broken.Validate(), ErrInvalidState))
+	broken = buf.Clone().(*RingBuf[int])
+	broken.mask = 2
+	assert.True(t, errors.Is(broken.Validate(), ErrInvalidState))
+	assert.True(t, errors.Is(new(RingBuf[int]).Validate(), ErrInvalidState))
+	assert.NoError(t, NewRingBuf[int](0).Validate())
+}
+
+func TestRingBufferErrorTypes(t *testing.T) {
+	buf := NewRingBuf[int](2)
+	buf.ResetAt(10)
+	assert.NoError(t, buf.Append(10))
+	assert.NoError(t, buf.Append(11))
+
+	err := buf.Append(12)
+	var overflow OverflowError
+	assert.True(t, errors.As(err, &overflow))
+	assert.Equal(t, OverflowError{Oldest: 10, Capacity: 2}, overflow)
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.False(t, errors.Is(err, ErrOutOfRange))
+
+	_, err = buf.Get(20)
+	var outOfRange OutOfRangeError
+	assert.True(t, errors.As(err, &outOfRange))
+	assert.Equal(t, OutOfRangeError{Requested: 20, Low: 10, High: 12}, outOfRange)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.Equal(t, "out of range: 20 not in range [10, 12)", err.Error())
+
+	err = buf.Drop(12)
+	assert.True(t, errors.As(err, &outOfRange))
+	assert.Equal(t, Position(12), outOfRange.Requested)
+}
+
+func TestRingBufferOutOfRangeAllocs(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	assert.NoError(t, buf.Append(0))
+	// not allocation-free: boxing the OutOfRangeError in an error allocates,
+	// but the message is not formatted until Error is called
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = buf.ToSlice(10)
+	})
+	assert.Equal(t, 1.0, allocs)
+	allocs = testing.AllocsPerRun(100, func() {
+		_, _ = buf.Get(10)
+	})
+	assert.Equal(t, 1.0, allocs)
+}
+
+func TestSyncBufIteratorCopyOnWrite(t *testing.T) {
+	ring := NewRingBuf[int](4)
+	buf := NewSyncBuf[int](ring)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	iter, err := buf.Iterator(2)
+	assert.NoError(t, err)
+	array := &ring.buf[0]
+
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(4)) // overwrites position 0, not iterated
+	assert.True(t, array == &ring.buf[0])
+	assert.NoError(t, buf.Append(5)) // overwrites position 1, not iterated
+	assert.True(t, array == &ring.buf[0])
+
+	assert.NoError(t, buf.Drop(3))
+	assert.NoError(t, buf.Append(6)) // overwrites position 2
+	assert.False(t, array == &ring.buf[0])
+	assert.Equal(t, []int{2, 3}, iter.ToSlice())
+	items, err := buf.ToSlice(4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{4, 5, 6}, items)
+
+	iter, err = buf.Iterator(4)
+	assert.NoError(t, err)
+	buf.Reset()
+	assert.Equal(t, []int{4, 5, 6}, iter.ToSlice())
+}
+
+func TestSyncBufIteratorClose(t *testing.T) {
+	ring := NewRingBuf[int](2)
+	buf := NewSyncBuf[int](ring)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	array := &ring.buf[0]
+	open, err := buf.Iterator(0)
+	assert.NoError(t, err)
+	closed, err := buf.Iterator(0)
+	assert.NoError(t, err)
+	assert.NoError(t, closed.Close())
+
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(2))
+	assert.False(t, array == &ring.buf[0]) // open is still aliasing
+	assert.Equal(t, []int{0, 1}, open.ToSlice())
+	assert.NoError(t, open.Close())
+
+	array = &ring.buf[0]
+	iter, err := buf.Iterator(1)
+	assert.NoError(t, err)
+	assert.NoError(t, iter.Close())
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3))
+	assert.True(t, array == &ring.buf[0])
+}
+
+func TestSyncBufIteratorCopyOnWriteZeroing(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](4, WithZeroing[int]()))
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i+1))
+	}
+	iter, err := buf.Iterator(0)
+	assert.NoError(t, err)
+	assert.NoError(t, buf.Drop(3))
+	assert.Equal(t, []int{1, 2, 3, 4}, iter.ToSlice())
+}
+
+func TestSyncBufIteratorConcurrent(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](8))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			if buf.Free() == 0 {
+				assert.NoError(t, buf.Drop(buf.FirstPosition()))
+			}
+			assert.NoError(t, buf.Append(i))
+			runtime.Gosched()
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		iter, err := buf.Iterator(buf.FirstPosition())
+		if err != nil {
+			continue // dropped in between
+		}
+		for iter.Scan() {
+			assert.Equal(t, int(iter.Position()), iter.Item())
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestSyncBufIteratorCloseConcurrent(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](8, WithOverwrite[int]()))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			assert.NoError(t, buf.Append(i))
+			runtime.Gosched()
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		iter, err := buf.Iterator(buf.FirstPosition())
+		if err != nil {
+			continue // evicted in between
+		}
+		for iter.Scan() {
+			assert.Equal(t, int(iter.Position()), iter.Item())
+		}
+		assert.NoError(t, iter.Close())
+		runtime.Gosched()
+	}
+}
@@ -0,0 +1,191 @@
+package ringbuf
+
+import (
+	"io"
+)
+
+func NewByteRing(size int) *ByteRing {
+	return &ByteRing{
+		RingBuf: NewRingBuf[byte](size),
+	}
+}
+
+// ByteRing is a RingBuf of bytes with a read cursor. Write appends bytes,
+// Read and ReadByte consume them from the cursor, and the consumed bytes are
+// kept until they are dropped, e.g. when a network peer acknowledges them.
+// Dropping bytes that have not been read yet, by any of the drop methods or
+// by an overwriting append, moves the read cursor past them.
+type ByteRing struct {
+	*RingBuf[byte]
+	read Position
+}
+
+var (
+	_ io.Reader     = (*ByteRing)(nil)
+	_ io.Writer     = (*ByteRing)(nil)
+	_ io.ByteReader = (*ByteRing)(nil)
+	_ io.ReaderFrom = (*ByteRing)(nil)
+	_ io.WriterTo   = (*ByteRing)(nil)
+)
+
+// Write appends as many bytes of p as fit and returns ErrBufferOverflow if
+// not all of them did.
+func (b *ByteRing) Write(p []byte) (int, error) {
+	n := len(p)
+	if free := b.Free(); free < n {
+		n = free
+	}
+	if _, err := b.AppendAll(p[:n]); err != nil {
+		return 0, err
+	}
+	if n < len(p) {
+		return n, b.stats.fail(errOverflow(b.FirstPosition(), b.Cap()))
+	}
+	return n, nil
+}
+
+func (b *ByteRing) Read(p []byte) (int, error) {
+	if b.cursor() == b.NextPosition() {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n, err := b.CopyTo(p, b.read)
+	b.read += Position(n)
+	return n, err
+}
+
+func (b *ByteRing) ReadByte() (byte, error) {
+	if b.cursor() == b.NextPosition() {
+		return 0, io.EOF
+	}
+	c, err := b.Get(b.read)
+	if err != nil {
+		return 0, err
+	}
+	b.read++
+	return c, nil
+}
+
+// ReadFrom reads from r directly into the free space until r returns io.EOF,
+// appending the bytes read as Write does. It returns ErrBufferOverflow if the
+// buffer fills up first, and ErrSealed if it is sealed.
+func (b *ByteRing) ReadFrom(r io.Reader) (int64, error) {
+	if b.sealed {
+		return 0, ErrSealed
+	}
+	var total int64
+	for {
+		head, _ := b.free()
+		if len(head) == 0 {
+			return total, b.errOverflow()
+		}
+		n, err := r.Read(head)
+		b.appended(n)
+		total += int64(n)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// WriteTo writes the unread bytes to w, at most two writes across the wrap
+// point, and advances the read cursor by what w accepted.
+func (b *ByteRing) WriteTo(w io.Writer) (int64, error) {
+	head, tail, err := b.iter(b.cursor())
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, p := range [][]byte{head, tail} {
+		if len(p) == 0 {
+			continue
+		}
+		n, err := w.Write(p)
+		b.read += Position(n)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		if n < len(p) {
+			return total, io.ErrShortWrite
+		}
+	}
+	return total, nil
+}
+
+// appended appends the n bytes read into the slots returned by free.
+func (b *ByteRing) appended(n int) {
+	if n == 0 {
+		return
+	}
+	start := b.NextPosition()
+	b.skip(n)
+	for pos := start; pos-b.NextPosition() < 0; pos++ {
+		i, _ := b.slot(pos)
+		if b.holes != nil {
+			b.holes[i] = false
+		}
+		if b.onAppend != nil {
+			b.onAppend(pos, b.buf[i])
+		}
+	}
+	b.stats.appended(n, b.Len())
+	b.modified()
+}
+
+// free returns the slots the next Free() bytes will be written to.
+func (b *ByteRing) free() ([]byte, []byte) {
+	n := b.Free()
+	i := b.wrap(b.next)
+	if n <= len(b.buf)-i {
+		return b.buf[i : i+n], nil
+	}
+	return b.buf[i:], b.buf[:n-(len(b.buf)-i)]
+}
+
+// ReadPosition returns the position of the next byte to be read.
+func (b *ByteRing) ReadPosition() Position {
+	return b.cursor()
+}
+
+// Unread returns the number of bytes not read yet.
+func (b *ByteRing) Unread() int {
+	return int(b.NextPosition() - b.cursor())
+}
+
+// cursor moves the read cursor past the bytes dropped before being read, and
+// returns it.
+func (b *ByteRing) cursor() Position {
+	if first := b.FirstPosition(); b.read-first < 0 {
+		b.read = first
+	}
+	return b.read
+}
+
+func (b *ByteRing) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *ByteRing) ResetAt(start Position) {
+	b.RingBuf.ResetAt(start)
+	b.read = start
+}
+
+func (b *ByteRing) Clone() Buffer[byte] {
+	return &ByteRing{
+		RingBuf: b.RingBuf.Clone().(*RingBuf[byte]),
+		read:    b.read,
+	}
+}
+
+// CloneFunc is like RingBuf.CloneFunc but the clone keeps the read cursor.
+func (b *ByteRing) CloneFunc(copyItem func(item byte) byte) Buffer[byte] {
+	clone := b.Clone().(*ByteRing)
+	clone.copyItems(copyItem)
+	return clone
+}
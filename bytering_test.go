@@ -0,0 +1,217 @@
+package ringbuf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteRing(t *testing.T) {
+	buf := NewByteRing(8)
+	n, err := buf.Write([]byte("hello"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	p := make([]byte, 3)
+	n, err = buf.Read(p)
+	assert.NoError(t, err)
+	assert.Equal(t, "hel", string(p[:n]))
+	assert.Equal(t, Position(3), buf.ReadPosition())
+	assert.Equal(t, 2, buf.Unread())
+
+	n, err = buf.Write([]byte(" world"))
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, 3, n)
+
+	assert.NoError(t, buf.Drop(2)) // acknowledge "hel"
+	n, err = buf.Write([]byte("rld"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	rest, err := io.ReadAll(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "lo world", string(rest))
+
+	n, err = buf.Read(p)
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, n)
+
+	retained, err := buf.ToSlice(buf.FirstPosition())
+	assert.NoError(t, err)
+	assert.Equal(t, "lo world", string(retained))
+}
+
+func TestByteRingReadByte(t *testing.T) {
+	buf := NewByteRing(4)
+	_, err := buf.ReadByte()
+	assert.Equal(t, io.EOF, err)
+
+	_, err = buf.Write([]byte("ab"))
+	assert.NoError(t, err)
+	c, err := buf.ReadByte()
+	assert.NoError(t, err)
+	assert.Equal(t, byte('a'), c)
+
+	line, err := bufio.NewReader(buf).ReadString('\n')
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, "b", line)
+}
+
+func TestByteRingDropUnread(t *testing.T) {
+	buf := NewByteRing(4)
+	_, err := buf.Write([]byte("abcd"))
+	assert.NoError(t, err)
+	assert.NoError(t, buf.Drop(1))
+	assert.Equal(t, Position(2), buf.ReadPosition())
+	c, err := buf.ReadByte()
+	assert.NoError(t, err)
+	assert.Equal(t, byte('c'), c)
+
+	buf.ResetAt(100)
+	assert.Equal(t, Position(100), buf.ReadPosition())
+	_, err = buf.Write([]byte("x"))
+	assert.NoError(t, err)
+	c, err = buf.ReadByte()
+	assert.NoError(t, err)
+	assert.Equal(t, byte('x'), c)
+}
+
+func TestByteRingDropMethods(t *testing.T) {
+	for _, drop := range []func(buf *ByteRing){
+		func(buf *ByteRing) { assert.NoError(t, buf.DropN(2)) },
+		func(buf *ByteRing) { assert.NoError(t, buf.DropIfHigher(1)) },
+		func(buf *ByteRing) { assert.NoError(t, buf.Roll(1)) },
+		func(buf *ByteRing) { buf.DropWhile(func(pos Position, c byte) bool { return c < 'c' }) },
+		func(buf *ByteRing) {
+			_, _, err := buf.PopFront()
+			assert.NoError(t, err)
+			_, _, err = buf.PopFront()
+			assert.NoError(t, err)
+		},
+	} {
+		buf := NewByteRing(4)
+		_, err := buf.Write([]byte("abcd"))
+		assert.NoError(t, err)
+		drop(buf)
+		assert.Equal(t, Position(2), buf.ReadPosition())
+		assert.Equal(t, 2, buf.Unread())
+		rest, err := io.ReadAll(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "cd", string(rest))
+	}
+
+	buf := NewByteRing(4)
+	_, err := buf.Write([]byte("abcd"))
+	assert.NoError(t, err)
+	assert.NoError(t, buf.DropAll())
+	assert.Equal(t, 0, buf.Unread())
+	_, err = buf.ReadByte()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestByteRingClone(t *testing.T) {
+	buf := NewByteRing(4)
+	_, err := buf.Write([]byte("abc"))
+	assert.NoError(t, err)
+	_, err = buf.ReadByte()
+	assert.NoError(t, err)
+
+	clone := buf.Clone().(*ByteRing)
+	_, err = buf.ReadByte()
+	assert.NoError(t, err)
+	rest, err := io.ReadAll(clone)
+	assert.NoError(t, err)
+	assert.Equal(t, "bc", string(rest))
+}
+
+func TestByteRingCloneFunc(t *testing.T) {
+	buf := NewByteRing(4)
+	_, err := buf.Write([]byte("abc"))
+	assert.NoError(t, err)
+	_, err = buf.ReadByte()
+	assert.NoError(t, err)
+
+	clone := buf.CloneFunc(func(item byte) byte { return item - 'a' + 'A' }).(*ByteRing)
+	rest, err := io.ReadAll(clone)
+	assert.NoError(t, err)
+	assert.Equal(t, "BC", string(rest))
+	rest, err = io.ReadAll(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "bc", string(rest))
+}
+
+func TestByteRingReadFrom(t *testing.T) {
+	buf := NewByteRing(8)
+	_, err := buf.Write([]byte("abcde"))
+	assert.NoError(t, err)
+	assert.NoError(t, buf.Drop(3))
+
+	n, err := buf.ReadFrom(strings.NewReader("fghij"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+	n, err = buf.ReadFrom(strings.NewReader("klmnop"))
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, int64(2), n)
+
+	var w bytes.Buffer
+	n, err = buf.WriteTo(&w)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), n)
+	assert.Equal(t, "efghijkl", w.String())
+	assert.Equal(t, 0, buf.Unread())
+
+	n, err = buf.WriteTo(&w)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+}
+
+func TestByteRingReadFromAppends(t *testing.T) {
+	buf := NewByteRing(8)
+	var appended []byte
+	buf.onAppend = func(pos Position, item byte) {
+		appended = append(appended, item)
+	}
+	_, err := buf.Write([]byte("ab"))
+	assert.NoError(t, err)
+	iter, err := buf.Iterator(0)
+	assert.NoError(t, err)
+
+	_, err = buf.ReadFrom(strings.NewReader("cd"))
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd", string(appended))
+	assert.Equal(t, uint64(4), buf.Stats().Appends)
+	assert.False(t, iter.Scan())
+	assert.True(t, errors.Is(iter.Err(), ErrConcurrentModification))
+
+	buf.Seal()
+	n, err := buf.ReadFrom(strings.NewReader("ef"))
+	assert.True(t, errors.Is(err, ErrSealed))
+	assert.Equal(t, int64(0), n)
+	assert.Equal(t, 4, buf.Len())
+}
+
+type shortWriter struct{ max int }
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > w.max {
+		return w.max, nil
+	}
+	return len(p), nil
+}
+
+func TestByteRingWriteToShort(t *testing.T) {
+	buf := NewByteRing(4)
+	_, err := buf.Write([]byte("abcd"))
+	assert.NoError(t, err)
+	n, err := buf.WriteTo(&shortWriter{max: 3})
+	assert.Equal(t, io.ErrShortWrite, err)
+	assert.Equal(t, int64(3), n)
+	c, err := buf.ReadByte()
+	assert.NoError(t, err)
+	assert.Equal(t, byte('d'), c)
+}
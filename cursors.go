@@ -0,0 +1,210 @@
+package ringbuf
+
+import (
+	"fmt"
+	"sync"
+)
+
+// NewCursors returns Cursors dropping items from buf once every cursor has
+// committed past them.
+func NewCursors[F any](buf Buffer[F]) *Cursors[F] {
+	return &Cursors[F]{
+		buf:     buf,
+		cursors: map[string]Position{},
+		groups:  map[string]*Group[F]{},
+	}
+}
+
+// Cursors tracks the read position of named consumers of a Buffer and drops
+// the items that all of them have committed. Cursors is safe for concurrent
+// use as long as the Buffer is, e.g. a SyncBuf appended to concurrently.
+type Cursors[F any] struct {
+	mu      sync.Mutex
+	buf     Buffer[F]
+	cursors map[string]Position // next position to read by name
+	groups  map[string]*Group[F]
+}
+
+// Add registers a cursor at the first position of the buffer.
+func (c *Cursors[F]) Add(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.cursors[name]; ok {
+		return fmt.Errorf("%w: cursor %q exists", ErrInvalidState, name)
+	}
+	c.cursors[name] = c.buf.FirstPosition()
+	return nil
+}
+
+// Remove unregisters a cursor, dropping the items only it was holding back.
+func (c *Cursors[F]) Remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.cursors, name)
+	delete(c.groups, name)
+	c.drop()
+}
+
+// Position returns the next position the cursor will read.
+func (c *Cursors[F]) Position(name string) (Position, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pos, ok := c.cursors[name]
+	if !ok {
+		return 0, errUnknownCursor(name)
+	}
+	return pos, nil
+}
+
+// Lag returns the number of items the cursor has yet to read.
+func (c *Cursors[F]) Lag(name string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pos, ok := c.cursors[name]
+	if !ok {
+		return 0, errUnknownCursor(name)
+	}
+	return int(c.buf.NextPosition() - pos), nil
+}
+
+// Lags returns the lag of every cursor by name.
+func (c *Cursors[F]) Lags() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next := c.buf.NextPosition()
+	lags := make(map[string]int, len(c.cursors))
+	for name, pos := range c.cursors {
+		lags[name] = int(next - pos)
+	}
+	return lags
+}
+
+// Stats returns the Stats of the buffer with MaxLag set to the highest lag
+// of the cursors.
+func (c *Cursors[F]) Stats() Stats {
+	stats := c.buf.Stats()
+	for _, lag := range c.Lags() {
+		if stats.MaxLag < lag {
+			stats.MaxLag = lag
+		}
+	}
+	return stats
+}
+
+// Read returns up to max items from the cursor without moving it.
+func (c *Cursors[F]) Read(name string, max int) ([]F, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	pos, ok := c.cursors[name]
+	if !ok {
+		return nil, errUnknownCursor(name)
+	}
+	return c.buf.ToSliceN(pos, max)
+}
+
+// Commit moves the cursor to pos, marking the items before pos as consumed.
+// pos must be between the cursor and the next position of the buffer.
+func (c *Cursors[F]) Commit(name string, pos Position) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.groups[name]; ok {
+		return fmt.Errorf("%w: cursor %q is a group", ErrInvalidState, name)
+	}
+	return c.commit(name, pos)
+}
+
+func (c *Cursors[F]) commit(name string, pos Position) error {
+	cur, ok := c.cursors[name]
+	if !ok {
+		return errUnknownCursor(name)
+	}
+	if next := c.buf.NextPosition(); pos-cur < 0 || 0 < pos-next {
+		return errOutOfRange(pos, cur, next)
+	}
+	c.cursors[name] = pos
+	c.drop()
+	return nil
+}
+
+// Group returns the consumer group sharing the cursor name, registering it at
+// the first position of the buffer if needed.
+func (c *Cursors[F]) Group(name string) (*Group[F], error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if g, ok := c.groups[name]; ok {
+		return g, nil
+	}
+	pos, ok := c.cursors[name]
+	if ok {
+		return nil, fmt.Errorf("%w: cursor %q exists", ErrInvalidState, name)
+	}
+	pos = c.buf.FirstPosition()
+	c.cursors[name] = pos
+	g := &Group[F]{
+		c:     c,
+		name:  name,
+		claim: pos,
+		acked: ackSet{},
+	}
+	c.groups[name] = g
+	return g, nil
+}
+
+// Group hands each item to exactly one of the workers sharing it. Its cursor
+// advances over the items once they are acknowledged, in position order.
+type Group[F any] struct {
+	c     *Cursors[F]
+	name  string
+	claim Position // next position to hand out
+	acked ackSet
+}
+
+// Claim hands out the next item not claimed by another worker of the group.
+// It returns false if there is none.
+func (g *Group[F]) Claim() (Position, F, bool) {
+	g.c.mu.Lock()
+	defer g.c.mu.Unlock()
+	pos := g.claim
+	item, err := g.c.buf.Get(pos)
+	if err != nil {
+		var zero F
+		return 0, zero, false
+	}
+	g.claim++
+	return pos, item, true
+}
+
+// Ack confirms that the item at pos has been processed. The group cursor moves
+// past every item acknowledged so far without a gap.
+func (g *Group[F]) Ack(pos Position) error {
+	g.c.mu.Lock()
+	defer g.c.mu.Unlock()
+	cur := g.c.cursors[g.name]
+	if _, ok := g.acked[pos]; ok || pos-cur < 0 || 0 <= pos-g.claim {
+		return errOutOfRange(pos, cur, g.claim)
+	}
+	g.acked[pos] = struct{}{}
+	return g.c.commit(g.name, g.acked.advance(cur))
+}
+
+// drop drops the items before the lowest cursor.
+func (c *Cursors[F]) drop() {
+	if len(c.cursors) == 0 {
+		return
+	}
+	first := true
+	var low Position
+	for _, pos := range c.cursors {
+		if first || pos-low < 0 {
+			low = pos
+			first = false
+		}
+	}
+	if 0 < low-c.buf.FirstPosition() {
+		_ = c.buf.Drop(low - 1)
+	}
+}
+
+func errUnknownCursor(name string) error {
+	return fmt.Errorf("%w: unknown cursor %q", ErrInvalidState, name)
+}
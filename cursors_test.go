@@ -0,0 +1,127 @@
+package ringbuf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursors(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	cursors := NewCursors[int](buf)
+	assert.NoError(t, cursors.Add("a"))
+	assert.NoError(t, cursors.Add("b"))
+	assert.True(t, errors.Is(cursors.Add("a"), ErrInvalidState))
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+
+	items, err := cursors.Read("a", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, items)
+	assert.NoError(t, cursors.Commit("a", 3))
+	assert.Equal(t, Position(0), buf.FirstPosition()) // held back by b
+
+	assert.NoError(t, cursors.Commit("b", 1))
+	assert.Equal(t, Position(1), buf.FirstPosition())
+	assert.NoError(t, buf.Append(4))
+
+	pos, err := cursors.Position("b")
+	assert.NoError(t, err)
+	assert.Equal(t, Position(1), pos)
+	items, err = cursors.Read("b", 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, items)
+
+	cursors.Remove("b")
+	assert.Equal(t, Position(3), buf.FirstPosition())
+	assert.NoError(t, cursors.Commit("a", 5))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestCursorsLag(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](4))
+	cursors := NewCursors[int](buf)
+	assert.NoError(t, cursors.Add("a"))
+	assert.NoError(t, cursors.Add("b"))
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, cursors.Commit("a", 3))
+	lag, err := cursors.Lag("a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, lag)
+	_, err = cursors.Lag("c")
+	assert.True(t, errors.Is(err, ErrInvalidState))
+	assert.Equal(t, map[string]int{"a": 1, "b": 4}, cursors.Lags())
+	assert.Equal(t, 4, cursors.Stats().MaxLag)
+	assert.Equal(t, 4, cursors.Stats().Len)
+
+	metrics := NewInstrumented[int]("", buf)
+	metrics.TrackLags(cursors)
+	m := metrics.Metrics()
+	assert.Equal(t, 4, m.MaxLag)
+	assert.Equal(t, map[string]int{"a": 1, "b": 4}, m.Lags)
+}
+
+func TestCursorsErrors(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	cursors := NewCursors[int](buf)
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, cursors.Add("a"))
+
+	assert.True(t, errors.Is(cursors.Commit("x", 0), ErrInvalidState))
+	_, err := cursors.Read("x", 1)
+	assert.True(t, errors.Is(err, ErrInvalidState))
+	_, err = cursors.Position("x")
+	assert.True(t, errors.Is(err, ErrInvalidState))
+
+	err = cursors.Commit("a", 2)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.Equal(t, OutOfRangeError{Requested: 2, Low: 0, High: 1}, err)
+	assert.NoError(t, cursors.Commit("a", 1))
+	err = cursors.Commit("a", 0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.Equal(t, OutOfRangeError{Requested: 0, Low: 1, High: 1}, err)
+}
+
+func TestCursorsGroup(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	cursors := NewCursors[int](buf)
+	g, err := cursors.Group("workers")
+	assert.NoError(t, err)
+	same, err := cursors.Group("workers")
+	assert.NoError(t, err)
+	assert.Equal(t, g, same)
+	assert.True(t, errors.Is(cursors.Commit("workers", 0), ErrInvalidState))
+
+	_, _, ok := g.Claim()
+	assert.False(t, ok)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i*10))
+	}
+	pos0, item, ok := g.Claim()
+	assert.True(t, ok)
+	assert.Equal(t, Position(0), pos0)
+	assert.Equal(t, 0, item)
+	pos1, item, ok := g.Claim()
+	assert.True(t, ok)
+	assert.Equal(t, Position(1), pos1)
+	assert.Equal(t, 10, item)
+
+	assert.NoError(t, g.Ack(pos1))
+	assert.Equal(t, 3, buf.Len()) // 0 not acknowledged yet
+	assert.True(t, errors.Is(g.Ack(pos1), ErrOutOfRange))
+	assert.True(t, errors.Is(g.Ack(2), ErrOutOfRange)) // not claimed
+
+	assert.NoError(t, g.Ack(pos0))
+	pos, err := cursors.Position("workers")
+	assert.NoError(t, err)
+	assert.Equal(t, Position(2), pos)
+	assert.Equal(t, Position(2), buf.FirstPosition())
+
+	assert.NoError(t, cursors.Add("other"))
+	_, err = cursors.Group("other")
+	assert.True(t, errors.Is(err, ErrInvalidState))
+}
@@ -0,0 +1,5 @@
+//go:build ringbuf_debug
+
+package ringbuf
+
+const debug = true
@@ -0,0 +1,164 @@
+package ringbuf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+const (
+	walAppend byte = 'A'
+	walDrop   byte = 'D'
+	walReset  byte = 'R'
+)
+
+// NewDurableBuf returns a DurableBuf that applies mutations to buf and logs
+// them to wal, encoding items with codec.
+func NewDurableBuf[F any](buf Buffer[F], wal io.Writer, codec Codec[F]) *DurableBuf[F] {
+	return &DurableBuf[F]{
+		Buffer: buf,
+		wal:    wal,
+		codec:  codec,
+	}
+}
+
+// DurableBuf is a Buffer decorator that writes every Append, Drop, Reset and
+// ResetAt to a write-ahead log before applying it, so that Replay can rebuild
+// the buffer after a restart. A mutation whose log write fails returns the
+// write error and is not applied. An Append rejected by the buffer, e.g. with
+// ErrBufferOverflow, has already been logged, and Replay skips it as it is
+// rejected again.
+type DurableBuf[F any] struct {
+	Buffer[F]
+	wal   io.Writer
+	codec Codec[F]
+	err   error // last log write error from Reset or ResetAt
+}
+
+func (b *DurableBuf[F]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *DurableBuf[F]) overwrites() bool {
+	return overwrites(b.Buffer)
+}
+
+func (b *DurableBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *DurableBuf[F]) AppendPos(item F) (Position, error) {
+	p, err := b.codec.Encode(item)
+	if err != nil {
+		return b.NextPosition(), err
+	}
+	rec := appendUvarint([]byte{walAppend}, uint64(len(p)))
+	if err := b.write(append(rec, p...)); err != nil {
+		return b.NextPosition(), err
+	}
+	return b.Buffer.AppendPos(item)
+}
+
+// Drop logs and drops up to drop. A drop outside the retained items is not
+// logged, as it is either rejected or a no-op.
+func (b *DurableBuf[F]) Drop(drop Position) error {
+	if drop-b.FirstPosition() < 0 || 0 <= drop-b.NextPosition() {
+		return b.Buffer.Drop(drop)
+	}
+	if err := b.write(appendVarint([]byte{walDrop}, int64(drop))); err != nil {
+		return err
+	}
+	return b.Buffer.Drop(drop)
+}
+
+func (b *DurableBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+// ResetAt logs and resets the buffer. Any log write error is returned by Err,
+// and the buffer is then left as is.
+func (b *DurableBuf[F]) ResetAt(start Position) {
+	b.err = b.write(appendVarint([]byte{walReset}, int64(start)))
+	if b.err == nil {
+		b.Buffer.ResetAt(start)
+	}
+}
+
+// Err returns the log write error of the last Reset or ResetAt.
+func (b *DurableBuf[F]) Err() error {
+	return b.err
+}
+
+func (b *DurableBuf[F]) write(rec []byte) error {
+	_, err := b.wal.Write(rec)
+	return err
+}
+
+// Replay applies the records read from r to the buffer without logging them
+// again. It returns io.ErrUnexpectedEOF if the log ends with a partial record,
+// as left by a crash in the middle of a write.
+func (b *DurableBuf[F]) Replay(r io.Reader) error {
+	br := bufio.NewReader(r)
+	for {
+		op, err := br.ReadByte()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := b.replay(op, br); err != nil {
+			if errors.Is(err, io.EOF) {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+	}
+}
+
+func (b *DurableBuf[F]) replay(op byte, r *bufio.Reader) error {
+	switch op {
+	case walAppend:
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return err
+		}
+		if math.MaxInt64 < n {
+			return fmt.Errorf("%w: malformed log record", ErrInvalidState)
+		}
+		// grown as the bytes are read rather than trusting n
+		var p bytes.Buffer
+		if _, err := io.CopyN(&p, r, int64(n)); err != nil {
+			return err
+		}
+		item, err := b.codec.Decode(p.Bytes())
+		if err != nil {
+			return err
+		}
+		err = b.Buffer.Append(item)
+		if errors.Is(err, ErrBufferOverflow) || errors.Is(err, ErrSealed) {
+			return nil // rejected when it was logged as well
+		}
+		return err
+	case walDrop:
+		pos, err := binary.ReadVarint(r)
+		if err != nil {
+			return err
+		}
+		return b.Buffer.Drop(Position(pos))
+	case walReset:
+		pos, err := binary.ReadVarint(r)
+		if err != nil {
+			return err
+		}
+		b.Buffer.ResetAt(Position(pos))
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown log record %q", ErrInvalidState, op)
+	}
+}
@@ -0,0 +1,78 @@
+package ringbuf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurableBuf(t *testing.T) {
+	var wal bytes.Buffer
+	buf := NewDurableBuf[int](NewRingBuf[int](3), &wal, intCodec{})
+	buf.ResetAt(10)
+	assert.NoError(t, buf.Err())
+	for i := 10; i < 13; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.True(t, errors.Is(buf.Append(13), ErrBufferOverflow))
+	assert.NoError(t, buf.Drop(10))
+	assert.NoError(t, buf.Append(13))
+	assert.True(t, errors.Is(buf.Drop(20), ErrOutOfRange))
+
+	restored := NewDurableBuf[int](NewRingBuf[int](3), io.Discard, intCodec{})
+	assert.NoError(t, restored.Replay(bytes.NewReader(wal.Bytes())))
+	assert.Equal(t, Position(11), restored.FirstPosition())
+	items, err := restored.ToSlice(11)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{11, 12, 13}, items)
+}
+
+func TestDurableBufReplayErrors(t *testing.T) {
+	var wal bytes.Buffer
+	buf := NewDurableBuf[int](NewRingBuf[int](3), &wal, intCodec{})
+	assert.NoError(t, buf.Append(100))
+	assert.NoError(t, buf.Append(200))
+	data := wal.Bytes()
+
+	restored := NewDurableBuf[int](NewRingBuf[int](3), io.Discard, intCodec{})
+	assert.Equal(t, io.ErrUnexpectedEOF, restored.Replay(bytes.NewReader(data[:len(data)-1])))
+	items, err := restored.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{100}, items)
+
+	restored.Reset()
+	err = restored.Replay(bytes.NewReader([]byte{'X'}))
+	assert.True(t, errors.Is(err, ErrInvalidState))
+
+	// a corrupt length is not allocated up front
+	rec := appendUvarint([]byte{walAppend}, 1<<62)
+	assert.Equal(t, io.ErrUnexpectedEOF, restored.Replay(bytes.NewReader(append(rec, 1, 2, 3))))
+	rec = appendUvarint([]byte{walAppend}, math.MaxUint64)
+	assert.True(t, errors.Is(restored.Replay(bytes.NewReader(rec)), ErrInvalidState))
+	assert.Equal(t, 0, restored.Len())
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestDurableBufWriteError(t *testing.T) {
+	ring := NewRingBuf[int](3)
+	assert.NoError(t, ring.Append(1))
+	buf := NewDurableBuf[int](ring, failWriter{}, intCodec{})
+	assert.Equal(t, io.ErrClosedPipe, buf.Append(2))
+	assert.Equal(t, io.ErrClosedPipe, buf.Drop(0))
+	buf.Reset()
+	assert.Equal(t, io.ErrClosedPipe, buf.Err())
+
+	// nothing is applied without being logged
+	items, err := ring.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, items)
+}
@@ -0,0 +1,45 @@
+package ringbuf
+
+import (
+	"fmt"
+)
+
+// OutOfRangeError reports a position outside [Low, High). It matches
+// ErrOutOfRange with errors.Is. Its message is only formatted by Error, so
+// returning it costs a single allocation for boxing it in an error.
+type OutOfRangeError struct {
+	Requested Position
+	Low       Position
+	High      Position
+}
+
+func (e OutOfRangeError) Error() string {
+	return fmt.Sprintf("%v: %v not in range [%v, %v)", ErrOutOfRange, e.Requested, e.Low, e.High)
+}
+
+func (e OutOfRangeError) Is(target error) bool {
+	return target == ErrOutOfRange
+}
+
+// OverflowError reports a buffer of Capacity items that is full from Oldest.
+// It matches ErrBufferOverflow with errors.Is.
+type OverflowError struct {
+	Oldest   Position
+	Capacity int
+}
+
+func (e OverflowError) Error() string {
+	return fmt.Sprintf("%v: %v items from %v", ErrBufferOverflow, e.Capacity, e.Oldest)
+}
+
+func (e OverflowError) Is(target error) bool {
+	return target == ErrBufferOverflow
+}
+
+func errOutOfRange(pos, low, high Position) error {
+	return OutOfRangeError{Requested: pos, Low: low, High: high}
+}
+
+func errOverflow(oldest Position, capacity int) error {
+	return OverflowError{Oldest: oldest, Capacity: capacity}
+}
@@ -0,0 +1,129 @@
+package ringbuf
+
+// NewGrowableRingBuf returns a GrowableRingBuf holding size items that can
+// grow up to max items.
+func NewGrowableRingBuf[F any](size, max int, opts ...Option[F]) *GrowableRingBuf[F] {
+	return &GrowableRingBuf[F]{
+		RingBuf: NewRingBuf[F](size, opts...),
+		min:     size,
+		max:     max,
+	}
+}
+
+// GrowableRingBuf is a RingBuf that doubles its backing array when full,
+// until it reaches max items. Cap, Free and IsFull account for the growth up
+// to max, while Allocated returns the size of the backing array.
+type GrowableRingBuf[F any] struct {
+	*RingBuf[F]
+	min int
+	max int
+}
+
+// Roll is like RingBuf.Roll but grows up to max items for the items to fit.
+func (b *GrowableRingBuf[F]) Roll(drop Position, items ...F) error {
+	if err := b.checkRollCap(drop, len(items), b.Cap()); err != nil {
+		return err
+	}
+	if err := b.Drop(drop); err != nil {
+		return err
+	}
+	_, err := b.AppendAll(items)
+	return err
+}
+
+func (b *GrowableRingBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *GrowableRingBuf[F]) AppendPos(item F) (Position, error) {
+	b.grow(1)
+	return b.RingBuf.AppendPos(item)
+}
+
+func (b *GrowableRingBuf[F]) AppendAll(items []F) (Position, error) {
+	b.grow(len(items))
+	return b.RingBuf.AppendAll(items)
+}
+
+// AppendEvict is like RingBuf.AppendEvict but only evicts once the buffer
+// has grown to max items.
+func (b *GrowableRingBuf[F]) AppendEvict(item F) (evicted F, evictedPos Position, didEvict bool) {
+	b.grow(1)
+	return b.RingBuf.AppendEvict(item)
+}
+
+// InsertAt is like RingBuf.InsertAt but grows up to max items for pos to be
+// within the capacity.
+func (b *GrowableRingBuf[F]) InsertAt(pos Position, item F) error {
+	if n := int(pos-b.NextPosition()) + 1; 0 < n && 0 <= pos-b.FirstPosition() {
+		b.grow(n)
+	}
+	return b.RingBuf.InsertAt(pos, item)
+}
+
+func (b *GrowableRingBuf[F]) Clone() Buffer[F] {
+	return &GrowableRingBuf[F]{
+		RingBuf: b.RingBuf.Clone().(*RingBuf[F]),
+		min:     b.min,
+		max:     b.max,
+	}
+}
+
+// CloneFunc is like RingBuf.CloneFunc but the clone keeps growing up to max.
+func (b *GrowableRingBuf[F]) CloneFunc(copyItem func(item F) F) Buffer[F] {
+	clone := b.Clone().(*GrowableRingBuf[F])
+	clone.copyItems(copyItem)
+	return clone
+}
+
+// Compact shrinks the backing array to the retained items, but not below the
+// initial size.
+func (b *GrowableRingBuf[F]) Compact() {
+	size := b.Len()
+	if size < b.min {
+		size = b.min
+	}
+	if size < b.Allocated() {
+		b.resize(size)
+	}
+}
+
+// Cap returns the number of items the buffer can hold once grown to max.
+func (b *GrowableRingBuf[F]) Cap() int {
+	if b.max < b.Allocated() {
+		return b.Allocated()
+	}
+	return b.max
+}
+
+// Allocated returns the number of items the backing array holds.
+func (b *GrowableRingBuf[F]) Allocated() int {
+	return b.RingBuf.Cap()
+}
+
+func (b *GrowableRingBuf[F]) Free() int {
+	return b.Cap() - b.Len()
+}
+
+func (b *GrowableRingBuf[F]) IsFull() bool {
+	return b.Free() <= 0
+}
+
+// grow doubles the capacity until n more items fit or max is reached.
+func (b *GrowableRingBuf[F]) grow(n int) {
+	if n <= b.RingBuf.Free() || b.max <= b.Allocated() {
+		return
+	}
+	size := b.Allocated()
+	if size == 0 {
+		size = 1
+	}
+	for size < b.Len()+n && size < b.max {
+		size *= 2
+	}
+	if b.max < size {
+		size = b.max
+	}
+	b.resize(size)
+}
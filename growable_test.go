@@ -0,0 +1,172 @@
+package ringbuf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrowableRingBuf(t *testing.T) {
+	buf := NewGrowableRingBuf[int](2, 5)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, 4, buf.Allocated())
+
+	assert.NoError(t, buf.Append(3))
+	assert.NoError(t, buf.Append(4))
+	assert.Equal(t, 5, buf.Allocated())
+	assert.True(t, errors.Is(buf.Append(5), ErrBufferOverflow))
+
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, items)
+
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(5))
+	assert.NoError(t, buf.Append(6))
+	items, err = buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4, 5, 6}, items)
+	_, err = buf.ToSlice(1)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
+
+func TestGrowableRingBufWrapped(t *testing.T) {
+	buf := NewGrowableRingBuf[int](3, 12)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3)) // wrap around
+	assert.NoError(t, buf.Append(4))
+	assert.Equal(t, 3, buf.Allocated())
+
+	pos, err := buf.AppendAll([]int{5, 6, 7, 8, 9})
+	assert.NoError(t, err)
+	assert.Equal(t, Position(5), pos)
+	assert.Equal(t, 12, buf.Allocated())
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9}, items)
+	assert.Equal(t, Position(2), buf.FirstPosition())
+	assert.Equal(t, Position(10), buf.NextPosition())
+
+	clone := buf.Clone()
+	assert.NoError(t, clone.Append(10))
+	assert.Equal(t, 8, buf.Len())
+	assert.Equal(t, 9, clone.Len())
+}
+
+func TestGrowableRingBufEmpty(t *testing.T) {
+	buf := NewGrowableRingBuf[int](1, 4)
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(1))
+	assert.NoError(t, buf.Drop(1))
+	_, err := buf.AppendAll([]int{2, 3, 4})
+	assert.NoError(t, err)
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, items)
+	assert.Equal(t, 4, buf.Allocated())
+}
+
+func TestGrowableRingBufOverwrite(t *testing.T) {
+	buf := NewGrowableRingBuf[int](1, 2, WithOverwrite[int]())
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, 2, buf.Allocated())
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, items)
+}
+
+func TestGrowableRingBufCompact(t *testing.T) {
+	buf := NewGrowableRingBuf[int](2, 16)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, 16, buf.Allocated())
+
+	buf.Compact()
+	assert.Equal(t, 10, buf.Allocated())
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, items)
+
+	assert.NoError(t, buf.Drop(8))
+	buf.Compact()
+	assert.Equal(t, 2, buf.Allocated())
+	items, err = buf.ToSlice(9)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{9}, items)
+	assert.NoError(t, buf.Append(10))
+	assert.NoError(t, buf.Append(11))
+	assert.Equal(t, 4, buf.Allocated())
+
+	assert.NoError(t, buf.Drop(11))
+	buf.Compact()
+	assert.Equal(t, 2, buf.Allocated())
+	assert.Equal(t, Position(12), buf.NextPosition())
+	assert.NoError(t, buf.Append(12))
+	item, err := buf.Get(12)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, item)
+}
+
+func TestGrowableRingBufAppendEvict(t *testing.T) {
+	buf := NewGrowableRingBuf[int](1, 2)
+	_, _, didEvict := buf.AppendEvict(0)
+	assert.False(t, didEvict)
+	_, _, didEvict = buf.AppendEvict(1)
+	assert.False(t, didEvict)
+	assert.Equal(t, 2, buf.Len())
+	evicted, pos, didEvict := buf.AppendEvict(2)
+	assert.True(t, didEvict)
+	assert.Equal(t, 0, evicted)
+	assert.Equal(t, Position(0), pos)
+}
+
+func TestGrowableRingBufInsertAt(t *testing.T) {
+	buf := NewGrowableRingBuf[int](2, 8)
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.InsertAt(4, 4))
+	assert.Equal(t, []PositionRange{{Start: 1, End: 4}}, buf.Missing())
+	assert.NoError(t, buf.InsertAt(2, 2))
+	item, err := buf.Get(4)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, item)
+	assert.True(t, errors.Is(buf.InsertAt(8, 8), ErrBufferOverflow))
+}
+
+func TestGrowableRingBufIsFull(t *testing.T) {
+	buf := NewGrowableRingBuf[int](1, 3)
+	assert.Equal(t, 3, buf.Cap())
+	assert.Equal(t, 3, buf.Free())
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, buf.Append(i))
+		assert.False(t, buf.IsFull())
+	}
+	assert.Equal(t, 1, buf.Free())
+	assert.NoError(t, buf.Append(2))
+	assert.True(t, buf.IsFull())
+	assert.Equal(t, 0, buf.Free())
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+}
+
+func TestGrowableRingBufCloneFunc(t *testing.T) {
+	buf := NewGrowableRingBuf[int](2, 4)
+	assert.NoError(t, buf.Append(1))
+	clone := buf.CloneFunc(func(item int) int { return item * 10 }).(*GrowableRingBuf[int])
+	for i := 2; i < 5; i++ {
+		assert.NoError(t, clone.Append(i*10))
+	}
+	assert.True(t, errors.Is(clone.Append(50), ErrBufferOverflow))
+	items, err := clone.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10, 20, 30, 40}, items)
+	assert.Equal(t, 1, buf.Len())
+}
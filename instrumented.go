@@ -0,0 +1,110 @@
+package ringbuf
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// NewInstrumented returns an Instrumented decorating buf. If name is not
+// empty, the metrics are published with expvar under name, which panics if
+// the name is already in use. buf must be safe for concurrent use, e.g. a
+// SyncBuf, since the metrics are read by the expvar handler.
+func NewInstrumented[F any](name string, buf Buffer[F]) *Instrumented[F] {
+	b := &Instrumented[F]{
+		Buffer: buf,
+		now:    time.Now,
+		window: 10 * time.Second,
+	}
+	b.lastAt = b.now()
+	if name != "" {
+		expvar.Publish(name, expvar.Func(func() any {
+			return b.Metrics()
+		}))
+	}
+	return b
+}
+
+// Instrumented is a Buffer decorator exporting metrics derived from Stats.
+//
+// There is no prometheus.Collector so as not to make every user depend on
+// the Prometheus client. The counters of Metrics are monotonic, so that one
+// can be written with prometheus.CounterFunc and GaugeFunc, and the backend
+// can derive the rates from them.
+type Instrumented[F any] struct {
+	Buffer[F]
+	mu      sync.Mutex
+	now     func() time.Time
+	window  time.Duration // over which AppendRate is measured
+	appends uint64        // Stats().Appends at lastAt
+	lastAt  time.Time
+	rate    float64 // AppendRate of the last window
+	lags    LagSource
+}
+
+// LagSource reports the lag of named consumers, e.g. Cursors.
+type LagSource interface {
+	Lags() map[string]int
+}
+
+func (b *Instrumented[F]) overwrites() bool {
+	return overwrites(b.Buffer)
+}
+
+// TrackLags adds the lags reported by src to the metrics.
+func (b *Instrumented[F]) TrackLags(src LagSource) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lags = src
+}
+
+// Metrics are the values exported by Instrumented.
+type Metrics struct {
+	Len         int     `json:"len"`
+	Cap         int     `json:"cap"`
+	PeakLen     int     `json:"peak_len"`
+	Appends     uint64  `json:"appends"`
+	Drops       uint64  `json:"drops"`
+	Overflows   uint64  `json:"overflows"`
+	OutOfRanges uint64  `json:"out_of_ranges"`
+	AppendRate  float64 `json:"append_rate"` // appends per second over the last window
+
+	MaxLag int            `json:"max_lag"`
+	Lags   map[string]int `json:"lags,omitempty"` // by consumer, see TrackLags
+}
+
+// Metrics returns the current metrics. AppendRate is measured over windows
+// of at least 10 seconds, each starting when Metrics is first called after
+// the previous one ended, so that the callers do not reset it for each
+// other. It is 0 until the first window ends.
+func (b *Instrumented[F]) Metrics() Metrics {
+	stats := b.Stats()
+	m := Metrics{
+		Len:         stats.Len,
+		Cap:         b.Cap(),
+		PeakLen:     stats.PeakLen,
+		Appends:     stats.Appends,
+		Drops:       stats.Drops,
+		Overflows:   stats.Overflows,
+		OutOfRanges: stats.OutOfRanges,
+		MaxLag:      stats.MaxLag,
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lags != nil {
+		m.Lags = b.lags.Lags()
+		for _, lag := range m.Lags {
+			if m.MaxLag < lag {
+				m.MaxLag = lag
+			}
+		}
+	}
+	now := b.now()
+	if elapsed := now.Sub(b.lastAt); b.window <= elapsed && 0 < elapsed {
+		b.rate = float64(stats.Appends-b.appends) / elapsed.Seconds()
+		b.appends = stats.Appends
+		b.lastAt = now
+	}
+	m.AppendRate = b.rate
+	return m
+}
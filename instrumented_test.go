@@ -0,0 +1,55 @@
+package ringbuf
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrumented(t *testing.T) {
+	buf := NewInstrumented[int]("", NewSyncBuf[int](NewRingBuf[int](3)))
+	now := time.Unix(0, 0)
+	buf.now = func() time.Time { return now }
+	buf.lastAt = now
+	buf.window = 2 * time.Second
+
+	for i := 0; i < 4; i++ {
+		_ = buf.Append(i)
+	}
+	now = now.Add(time.Second)
+	assert.Equal(t, 0.0, buf.Metrics().AppendRate) // first window not ended
+	now = now.Add(time.Second)
+	assert.Equal(t, Metrics{
+		Len:        3,
+		Cap:        3,
+		PeakLen:    3,
+		Appends:    3,
+		Overflows:  1,
+		AppendRate: 1.5,
+	}, buf.Metrics())
+
+	// another reader within the window sees the same rate
+	assert.NoError(t, buf.Drop(0))
+	now = now.Add(time.Second)
+	m := buf.Metrics()
+	assert.Equal(t, uint64(1), m.Drops)
+	assert.Equal(t, 1.5, m.AppendRate)
+
+	now = now.Add(time.Second)
+	assert.Equal(t, 0.0, buf.Metrics().AppendRate)
+}
+
+func TestInstrumentedExpvar(t *testing.T) {
+	buf := NewInstrumented[int]("ringbuf_test_instrumented", NewSyncBuf[int](NewRingBuf[int](3)))
+	assert.NoError(t, buf.Append(1))
+
+	v := expvar.Get("ringbuf_test_instrumented")
+	var m Metrics
+	assert.NoError(t, json.Unmarshal([]byte(v.String()), &m))
+	assert.Equal(t, 1, m.Len)
+	assert.Equal(t, 3, m.Cap)
+	assert.Equal(t, uint64(1), m.Appends)
+}
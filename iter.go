@@ -0,0 +1,94 @@
+//go:build go1.23
+
+package ringbuf
+
+import "iter"
+
+// All returns an iterator over positions and items from start to the newest
+// item. It yields nothing if start is out of range.
+func (b *RingBuf[F]) All(start Position) iter.Seq2[Position, F] {
+	return func(yield func(Position, F) bool) {
+		head, tail, err := b.iter(start)
+		if err != nil {
+			return
+		}
+		pos := start
+		for _, s := range [][]F{head, tail} {
+			for _, item := range s {
+				if !yield(pos, item) {
+					return
+				}
+				pos++
+			}
+		}
+	}
+}
+
+func (b *RingBuf[F]) Values(start Position) iter.Seq[F] {
+	return func(yield func(F) bool) {
+		for _, item := range b.All(start) {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over positions and items from the newest item
+// down to start.
+func (b *RingBuf[F]) Backward(start Position) iter.Seq2[Position, F] {
+	return func(yield func(Position, F) bool) {
+		head, tail, err := b.iter(start)
+		if err != nil {
+			return
+		}
+		pos := b.NextPosition()
+		for _, s := range [][]F{tail, head} {
+			for i := len(s) - 1; i >= 0; i-- {
+				pos--
+				if !yield(pos, s[i]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// All iterates over a copy of the items taken when iteration begins.
+func (c *SyncBuf[F]) All(start Position) iter.Seq2[Position, F] {
+	return func(yield func(Position, F) bool) {
+		items, err := c.ToSlice(start)
+		if err != nil {
+			return
+		}
+		for i, item := range items {
+			if !yield(start+Position(i), item) {
+				return
+			}
+		}
+	}
+}
+
+func (c *SyncBuf[F]) Values(start Position) iter.Seq[F] {
+	return func(yield func(F) bool) {
+		for _, item := range c.All(start) {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+func (c *SyncBuf[F]) Backward(start Position) iter.Seq2[Position, F] {
+	return func(yield func(Position, F) bool) {
+		items, err := c.ToSlice(start)
+		if err != nil {
+			return
+		}
+		for i := len(items) - 1; i >= 0; i-- {
+			if !yield(start+Position(i), items[i]) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+//go:build go1.23
+
+package ringbuf
+
+import (
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type seqBuffer interface {
+	Buffer[int]
+	All(start Position) iter.Seq2[Position, int]
+	Values(start Position) iter.Seq[int]
+	Backward(start Position) iter.Seq2[Position, int]
+}
+
+func TestRingBufferAll(t *testing.T) {
+	checkSeq(t, NewRingBuf[int](4))
+}
+
+func TestSyncBufAll(t *testing.T) {
+	checkSeq(t, NewSyncBuf[int](NewRingBuf[int](4)))
+}
+
+func checkSeq(t *testing.T, buf seqBuffer) {
+	t.Helper()
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(4)) // wrap around
+	assert.NoError(t, buf.Append(5))
+
+	var positions []Position
+	var items []int
+	for pos, item := range buf.All(2) {
+		positions = append(positions, pos)
+		items = append(items, item)
+	}
+	assert.Equal(t, []Position{2, 3, 4, 5}, positions)
+	assert.Equal(t, []int{2, 3, 4, 5}, items)
+
+	assert.Equal(t, []int{3, 4, 5}, slices.Collect(buf.Values(3)))
+
+	positions, items = nil, nil
+	for pos, item := range buf.Backward(3) {
+		positions = append(positions, pos)
+		items = append(items, item)
+	}
+	assert.Equal(t, []Position{5, 4, 3}, positions)
+	assert.Equal(t, []int{5, 4, 3}, items)
+
+	for pos := range buf.All(2) {
+		if pos == 3 {
+			break
+		}
+	}
+	assert.Empty(t, slices.Collect(buf.Values(6)))
+	assert.Empty(t, slices.Collect(buf.Values(7)))
+}
@@ -0,0 +1,34 @@
+package ringbuf
+
+import (
+	"encoding/json"
+)
+
+// jsonBuf is the JSON form of a buffer: the retained items and the position
+// of the first one.
+type jsonBuf[F any] struct {
+	Base  Position `json:"base"`
+	Items []F      `json:"items"`
+}
+
+func marshalJSON[F any](buf Buffer[F]) ([]byte, error) {
+	base := buf.FirstPosition()
+	items, err := buf.ToSlice(base)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []F{}
+	}
+	return json.Marshal(jsonBuf[F]{Base: base, Items: items})
+}
+
+func (b *RingBuf[F]) MarshalJSON() ([]byte, error) {
+	return marshalJSON[F](b)
+}
+
+func (c *SyncBuf[F]) MarshalJSON() ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return marshalJSON(c.buf)
+}
@@ -0,0 +1,37 @@
+package ringbuf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingBufferMarshalJSON(t *testing.T) {
+	buf := NewRingBuf[string](3)
+	data, err := json.Marshal(buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"base": 0, "items": []}`, string(data))
+
+	for _, item := range []string{"a", "b", "c"} {
+		assert.NoError(t, buf.Append(item))
+	}
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append("d")) // wrap around
+	data, err = json.Marshal(buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"base": 1, "items": ["b", "c", "d"]}`, string(data))
+}
+
+func TestSyncBufMarshalJSON(t *testing.T) {
+	buf := NewSyncBuf[int](NewRingBuf[int](3))
+	buf.ResetAt(-5)
+	data, err := json.Marshal(buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"base": -5, "items": []}`, string(data))
+
+	assert.NoError(t, buf.Append(1))
+	data, err = json.Marshal(buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"base": -5, "items": [1]}`, string(data))
+}
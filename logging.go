@@ -0,0 +1,85 @@
+package ringbuf
+
+import "time"
+
+// Logger receives a message with alternating keys and values, like
+// slog.Logger.Info. See NewSlogLogger for an adapter.
+type Logger interface {
+	Log(msg string, keyvals ...any)
+}
+
+// WithLogger makes the buffer log the appends rejected with
+// ErrBufferOverflow and the calls to Drop releasing at least largeDrop items,
+// each at most once per interval so that an overflow storm does not hide the
+// large drops. The number of messages suppressed in between is logged with
+// the next one. A clone of the buffer is rate-limited on its own.
+func WithLogger[F any](logger Logger, largeDrop int, interval time.Duration) Option[F] {
+	return func(b *RingBuf[F]) {
+		b.logger = &logLimiter{
+			logger:    logger,
+			largeDrop: largeDrop,
+			interval:  interval,
+			now:       time.Now,
+		}
+	}
+}
+
+// logLimiter rate-limits the messages of a RingBuf.
+type logLimiter struct {
+	logger    Logger
+	largeDrop int
+	interval  time.Duration
+	now       func() time.Time
+	overflow  logRate
+	drop      logRate
+}
+
+// logRate is the rate limit of one kind of message.
+type logRate struct {
+	last       time.Time
+	suppressed int
+}
+
+// clone returns a logLimiter with the same settings and no message logged
+// yet, or nil if l is nil.
+func (l *logLimiter) clone() *logLimiter {
+	if l == nil {
+		return nil
+	}
+	return &logLimiter{
+		logger:    l.logger,
+		largeDrop: l.largeDrop,
+		interval:  l.interval,
+		now:       l.now,
+	}
+}
+
+func (l *logLimiter) log(r *logRate, msg string, keyvals ...any) {
+	now := l.now()
+	if !r.last.IsZero() && now.Sub(r.last) < l.interval {
+		r.suppressed++
+		return
+	}
+	if 0 < r.suppressed {
+		keyvals = append(keyvals, "suppressed", r.suppressed)
+	}
+	r.last = now
+	r.suppressed = 0
+	l.logger.Log(msg, keyvals...)
+}
+
+// errOverflow counts and logs an append rejected with ErrBufferOverflow.
+func (b *RingBuf[F]) errOverflow() error {
+	first := b.FirstPosition()
+	if b.logger != nil {
+		b.logger.log(&b.logger.overflow, "ringbuf: buffer overflow", "first", first, "next", b.NextPosition(), "cap", len(b.buf))
+	}
+	return b.stats.fail(errOverflow(first, len(b.buf)))
+}
+
+// logDrop logs a Drop of n items up to drop if n is large.
+func (b *RingBuf[F]) logDrop(drop Position, n int) {
+	if b.logger != nil && 0 < n && b.logger.largeDrop <= n {
+		b.logger.log(&b.logger.drop, "ringbuf: large drop", "from", drop-Position(n)+1, "to", drop, "count", n)
+	}
+}
@@ -0,0 +1,68 @@
+package ringbuf
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Log(msg string, keyvals ...any) {
+	l.lines = append(l.lines, fmt.Sprintf("%s %v", msg, keyvals))
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := &recordingLogger{}
+	buf := NewRingBuf[int](3, WithLogger[int](logger, 2, time.Second))
+	now := time.Unix(0, 0)
+	buf.logger.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow)) // suppressed
+	now = now.Add(time.Second)
+	assert.NoError(t, buf.Drop(0)) // small
+	assert.NoError(t, buf.Drop(2))
+	now = now.Add(time.Second)
+	_, err := buf.AppendAll([]int{3, 4, 5, 6})
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+
+	assert.Equal(t, []string{
+		"ringbuf: buffer overflow [first 0 next 3 cap 3]",
+		"ringbuf: large drop [from 1 to 2 count 2]",
+		"ringbuf: buffer overflow [first 3 next 3 cap 3 suppressed 1]",
+	}, logger.lines)
+	assert.Equal(t, uint64(3), buf.Stats().Overflows)
+}
+
+func TestWithLoggerSeparateLimits(t *testing.T) {
+	logger := &recordingLogger{}
+	buf := NewRingBuf[int](2, WithLogger[int](logger, 2, time.Second))
+	now := time.Unix(0, 0)
+	buf.logger.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.True(t, errors.Is(buf.Append(2), ErrBufferOverflow))
+	assert.NoError(t, buf.Drop(1)) // not hidden by the overflow
+
+	clone := buf.Clone().(*RingBuf[int])
+	assert.NotSame(t, buf.logger, clone.logger)
+	assert.NoError(t, clone.Append(2))
+	assert.NoError(t, clone.Append(3))
+	assert.True(t, errors.Is(clone.Append(4), ErrBufferOverflow)) // not suppressed by buf
+
+	assert.Equal(t, []string{
+		"ringbuf: buffer overflow [first 0 next 2 cap 2]",
+		"ringbuf: large drop [from 0 to 1 count 2]",
+		"ringbuf: buffer overflow [first 2 next 4 cap 2]",
+	}, logger.lines)
+}
@@ -0,0 +1,73 @@
+package ringbuf
+
+// MergeIterators returns a MergeIterator yielding the items of its in
+// position order, e.g. to replay a stream sharded across several buffers.
+// Items at the same position are yielded in the order of its.
+func MergeIterators[F any](its ...*Iterator[F]) *MergeIterator[F] {
+	m := &MergeIterator[F]{
+		its:  its,
+		live: make([]bool, len(its)),
+		cur:  -1,
+	}
+	for i, it := range its {
+		m.live[i] = it.Scan()
+	}
+	return m
+}
+
+// MergeIterator merges several iterators by position. Picking the next item
+// is linear in the number of iterators, which is small for sharded buffers.
+type MergeIterator[F any] struct {
+	its  []*Iterator[F]
+	live []bool // whether its[i] holds an item not yielded yet
+	cur  int    // index of the iterator holding the current item, or -1
+}
+
+func (m *MergeIterator[F]) Scan() bool {
+	if 0 <= m.cur {
+		m.live[m.cur] = m.its[m.cur].Scan()
+	}
+	m.cur = -1
+	for i, it := range m.its {
+		if m.live[i] && (m.cur < 0 || it.Position()-m.its[m.cur].Position() < 0) {
+			m.cur = i
+		}
+	}
+	return 0 <= m.cur
+}
+
+func (m *MergeIterator[F]) Item() F {
+	return m.its[m.cur].Item()
+}
+
+func (m *MergeIterator[F]) Position() Position {
+	return m.its[m.cur].Position()
+}
+
+// Err returns the first error of the merged iterators, see Iterator.Err.
+func (m *MergeIterator[F]) Err() error {
+	for _, it := range m.its {
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the merged iterators, after which Scan returns false.
+func (m *MergeIterator[F]) Close() error {
+	for i, it := range m.its {
+		it.Close()
+		m.live[i] = false
+	}
+	m.cur = -1
+	return nil
+}
+
+func (m *MergeIterator[F]) ToSlice() []F {
+	var ret []F
+	for m.Scan() {
+		ret = append(ret, m.Item())
+	}
+	return ret
+}
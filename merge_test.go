@@ -0,0 +1,65 @@
+package ringbuf
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeIterators(t *testing.T) {
+	even := NewRingBuf[int](3)
+	odd := NewRingBuf[int](3)
+	even.ResetAt(0)
+	odd.ResetAt(2)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, even.Append(i*10))
+		assert.NoError(t, odd.Append(i*10+1))
+	}
+	a, err := even.Iterator(0)
+	assert.NoError(t, err)
+	b, err := odd.Iterator(2)
+	assert.NoError(t, err)
+
+	m := MergeIterators[int](a, b, NewIteratorAt[int](1, []int{100}))
+	var positions []Position
+	var items []int
+	for m.Scan() {
+		positions = append(positions, m.Position())
+		items = append(items, m.Item())
+	}
+	assert.NoError(t, m.Err())
+	assert.Equal(t, []Position{0, 1, 1, 2, 2, 3, 4}, positions)
+	assert.Equal(t, []int{0, 10, 100, 20, 1, 11, 21}, items)
+	assert.False(t, m.Scan())
+
+	assert.Empty(t, MergeIterators[int]().ToSlice())
+	assert.Empty(t, MergeIterators[int](NewIterator[int]()).ToSlice())
+
+	wrap := MergeIterators[int](
+		NewIteratorAt[int](math.MinInt32, []int{2, 3}),
+		NewIteratorAt[int](math.MaxInt32, []int{1}),
+	)
+	assert.Equal(t, []int{1, 2, 3}, wrap.ToSlice())
+
+	m = MergeIterators[int](NewIterator[int]([]int{0, 1}))
+	assert.True(t, m.Scan())
+	assert.NoError(t, m.Close())
+	assert.False(t, m.Scan())
+}
+
+func TestMergeIteratorsModification(t *testing.T) {
+	buf := NewRingBuf[int](3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	iter, err := buf.Iterator(0)
+	assert.NoError(t, err)
+	m := MergeIterators[int](NewIteratorAt[int](0, []int{-1}), iter)
+	assert.True(t, m.Scan())
+	assert.NoError(t, buf.Drop(0))
+	for m.Scan() {
+	}
+	assert.True(t, errors.Is(m.Err(), ErrConcurrentModification))
+}
@@ -0,0 +1,306 @@
+//go:build linux || darwin
+
+package ringbuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// FixedCodec converts items to and from exactly Size() bytes.
+type FixedCodec[F any] interface {
+	Size() int
+	Put(dst []byte, item F)
+	Get(src []byte) F
+}
+
+const (
+	mmapMagic    = "RINGBUF1"
+	mmapHeader   = 64 // header bytes before the first slot
+	mmapItemSize = 8
+	mmapSize     = 12
+	mmapOrigin   = 16
+	mmapHead     = 24
+	mmapTail     = 32
+)
+
+// OpenMmapRing opens the ring stored in the file at path, creating it with
+// room for size items if it does not exist. The positions are stored in the
+// file with the items, so a reopened ring continues where it left off.
+func OpenMmapRing[F any](path string, size int, codec FixedCodec[F]) (*MmapRing[F], error) {
+	if size <= 0 || codec.Size() <= 0 {
+		return nil, fmt.Errorf("%w: invalid size", ErrInvalidState)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	b, err := mmapRing(f, size, codec)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func mmapRing[F any](f *os.File, size int, codec FixedCodec[F]) (*MmapRing[F], error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	length := mmapHeader + size*codec.Size()
+	created := fi.Size() == 0
+	if created {
+		if err := f.Truncate(int64(length)); err != nil {
+			return nil, err
+		}
+	} else if fi.Size() != int64(length) {
+		return nil, fmt.Errorf("%w: file size %v, want %v", ErrInvalidState, fi.Size(), length)
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, length, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	b := &MmapRing[F]{
+		f:     f,
+		data:  data,
+		size:  size,
+		codec: codec,
+	}
+	if created {
+		copy(data, mmapMagic)
+		binary.LittleEndian.PutUint32(data[mmapItemSize:], uint32(codec.Size()))
+		binary.LittleEndian.PutUint32(data[mmapSize:], uint32(size))
+	} else if string(data[:len(mmapMagic)]) != mmapMagic ||
+		binary.LittleEndian.Uint32(data[mmapItemSize:]) != uint32(codec.Size()) ||
+		binary.LittleEndian.Uint32(data[mmapSize:]) != uint32(size) {
+		_ = syscall.Munmap(data)
+		return nil, fmt.Errorf("%w: header mismatch", ErrInvalidState)
+	}
+	return b, nil
+}
+
+// MmapRing is a Buffer of fixed-size items stored in a memory-mapped file, so
+// that the buffered items survive a crash of the process. Like SPSCBuf, only
+// the items after the last Drop can be read, and reads return copies. It is
+// not safe for concurrent use.
+type MmapRing[F any] struct {
+	stats counters
+	f     *os.File
+	data  []byte
+	size  int
+	codec FixedCodec[F]
+}
+
+// Sync flushes the file to stable storage.
+func (b *MmapRing[F]) Sync() error {
+	return b.f.Sync()
+}
+
+func (b *MmapRing[F]) Close() error {
+	err := syscall.Munmap(b.data)
+	b.data = nil
+	if cerr := b.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (b *MmapRing[F]) origin() Position {
+	return Position(binary.LittleEndian.Uint32(b.data[mmapOrigin:]))
+}
+
+func (b *MmapRing[F]) head() uint64 {
+	return binary.LittleEndian.Uint64(b.data[mmapHead:])
+}
+
+func (b *MmapRing[F]) tail() uint64 {
+	return binary.LittleEndian.Uint64(b.data[mmapTail:])
+}
+
+func (b *MmapRing[F]) position(count uint64) Position {
+	return b.origin() + Position(count)
+}
+
+func (b *MmapRing[F]) slot(count uint64) []byte {
+	n := b.codec.Size()
+	i := mmapHeader + int(count%uint64(b.size))*n
+	return b.data[i : i+n]
+}
+
+func (b *MmapRing[F]) Drop(drop Position) error {
+	head, tail := b.head(), b.tail()
+	n := int(drop - b.position(tail)) // drop - next
+	if 0 <= n {
+		return b.stats.fail(errOutOfRange(drop, b.position(head), b.position(tail)))
+	}
+	if target := tail + uint64(n+1); head < target && target <= tail {
+		binary.LittleEndian.PutUint64(b.data[mmapHead:], target)
+		b.stats.dropped(int(target - head))
+	}
+	return nil
+}
+
+func (b *MmapRing[F]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *MmapRing[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *MmapRing[F]) AppendPos(item F) (Position, error) {
+	head, tail := b.head(), b.tail()
+	if uint64(b.size) <= tail-head {
+		return b.position(tail), b.stats.fail(errOverflow(b.position(head), b.size))
+	}
+	b.codec.Put(b.slot(tail), item)
+	binary.LittleEndian.PutUint64(b.data[mmapTail:], tail+1)
+	b.stats.appended(1, int(tail+1-head))
+	return b.position(tail), nil
+}
+
+func (b *MmapRing[F]) Iterator(start Position) (*Iterator[F], error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	return NewIteratorAt[F](start, items), nil
+}
+
+func (b *MmapRing[F]) ToSlice(start Position) ([]F, error) {
+	return b.ToSliceN(start, b.size)
+}
+
+func (b *MmapRing[F]) ToSliceRange(start, end Position) ([]F, error) {
+	n := int(end - start)
+	if n < 0 || int(b.NextPosition()-start) < n {
+		low, high := b.Bounds()
+		return nil, b.stats.fail(errOutOfRange(end, low, high))
+	}
+	return b.ToSliceN(start, n)
+}
+
+func (b *MmapRing[F]) ToSliceN(start Position, max int) ([]F, error) {
+	begin, n, err := b.iter(start)
+	if err != nil {
+		return nil, err
+	}
+	if max < n {
+		n = max
+	}
+	if n < 0 {
+		n = 0
+	}
+	items := make([]F, n)
+	for i := range items {
+		items[i] = b.codec.Get(b.slot(begin + uint64(i)))
+	}
+	return items, nil
+}
+
+func (b *MmapRing[F]) CopyTo(dst []F, start Position) (int, error) {
+	begin, n, err := b.iter(start)
+	if err != nil {
+		return 0, err
+	}
+	if len(dst) < n {
+		n = len(dst)
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = b.codec.Get(b.slot(begin + uint64(i)))
+	}
+	return n, nil
+}
+
+func (b *MmapRing[F]) Get(pos Position) (F, error) {
+	var zero F
+	begin, n, err := b.iter(pos)
+	if err != nil {
+		return zero, err
+	}
+	if n == 0 {
+		low, high := b.Bounds()
+		return zero, b.stats.fail(errOutOfRange(pos, low, high))
+	}
+	return b.codec.Get(b.slot(begin)), nil
+}
+
+// iter returns the count of start and the number of items from start.
+func (b *MmapRing[F]) iter(start Position) (uint64, int, error) {
+	head, tail := b.head(), b.tail()
+	n := int(start - b.position(head))
+	if n < 0 || int(tail-head) < n {
+		return 0, 0, b.stats.fail(errOutOfRange(start, b.position(head), b.position(tail)))
+	}
+	return head + uint64(n), int(tail-head) - n, nil
+}
+
+func (b *MmapRing[F]) Bounds() (Position, Position) {
+	return b.FirstPosition(), b.NextPosition()
+}
+
+func (b *MmapRing[F]) FirstPosition() Position {
+	return b.position(b.head())
+}
+
+func (b *MmapRing[F]) NextPosition() Position {
+	return b.position(b.tail())
+}
+
+// Clone returns an in-memory RingBuf holding copies of the retained items.
+func (b *MmapRing[F]) Clone() Buffer[F] {
+	first := b.FirstPosition()
+	items, _ := b.ToSlice(first)
+	clone := NewRingBuf[F](b.size)
+	clone.ResetAt(first)
+	_, _ = clone.AppendAll(items)
+	clone.stats = b.stats.clone()
+	return clone
+}
+
+func (b *MmapRing[F]) Len() int {
+	return int(b.tail() - b.head())
+}
+
+func (b *MmapRing[F]) Cap() int {
+	return b.size
+}
+
+// MemoryUsage returns the size of the mapping, which is backed by the file
+// rather than the heap.
+func (b *MmapRing[F]) MemoryUsage() int64 {
+	return int64(len(b.data))
+}
+
+func (b *MmapRing[F]) Free() int {
+	return b.Cap() - b.Len()
+}
+
+func (b *MmapRing[F]) IsEmpty() bool {
+	return b.Len() <= 0
+}
+
+func (b *MmapRing[F]) IsFull() bool {
+	return b.Free() <= 0
+}
+
+// Stats returns the counters since the ring was opened. They are not stored
+// in the file.
+func (b *MmapRing[F]) Stats() Stats {
+	return b.stats.stats(b.Len())
+}
+
+func (b *MmapRing[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *MmapRing[F]) ResetAt(start Position) {
+	b.stats.dropped(b.Len())
+	binary.LittleEndian.PutUint32(b.data[mmapOrigin:], uint32(start))
+	binary.LittleEndian.PutUint64(b.data[mmapHead:], 0)
+	binary.LittleEndian.PutUint64(b.data[mmapTail:], 0)
+}
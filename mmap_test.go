@@ -0,0 +1,128 @@
+//go:build linux || darwin
+
+package ringbuf
+
+import (
+	"encoding/binary"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type uint32Codec struct{}
+
+func (uint32Codec) Size() int                   { return 4 }
+func (uint32Codec) Put(dst []byte, item uint32) { binary.LittleEndian.PutUint32(dst, item) }
+func (uint32Codec) Get(src []byte) uint32       { return binary.LittleEndian.Uint32(src) }
+
+func TestMmapRing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	buf, err := OpenMmapRing[uint32](path, 3, uint32Codec{})
+	assert.NoError(t, err)
+	buf.ResetAt(-2)
+	for i := uint32(0); i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+	assert.NoError(t, buf.Drop(-2))
+	assert.NoError(t, buf.Append(3)) // wrap around
+	assert.NoError(t, buf.Sync())
+	assert.NoError(t, buf.Close())
+
+	buf, err = OpenMmapRing[uint32](path, 3, uint32Codec{})
+	assert.NoError(t, err)
+	defer buf.Close()
+	assert.Equal(t, Position(-1), buf.FirstPosition())
+	assert.Equal(t, Position(2), buf.NextPosition())
+	items, err := buf.ToSlice(-1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{1, 2, 3}, items)
+
+	item, err := buf.Get(1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), item)
+	_, err = buf.Get(2)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+
+	items, err = buf.ToSliceRange(0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{2, 3}, items)
+
+	iter, err := buf.Iterator(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{2, 3}, iter.ToSlice())
+
+	clone := buf.Clone()
+	assert.NoError(t, buf.Drop(1))
+	items, err = clone.ToSlice(-1)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{1, 2, 3}, items)
+	assert.Equal(t, 0, buf.Len())
+}
+
+type intFixedCodec struct{}
+
+func (intFixedCodec) Size() int                { return 8 }
+func (intFixedCodec) Put(dst []byte, item int) { binary.LittleEndian.PutUint64(dst, uint64(item)) }
+func (intFixedCodec) Get(src []byte) int       { return int(binary.LittleEndian.Uint64(src)) }
+
+func newMmapRing(t *testing.T, size int) *MmapRing[int] {
+	t.Helper()
+	buf, err := OpenMmapRing[int](filepath.Join(t.TempDir(), "ring"), size, intFixedCodec{})
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = buf.Close() })
+	return buf
+}
+
+func TestMmapRingStaleDrop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	buf, err := OpenMmapRing[int](path, 8, intFixedCodec{})
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Drop(-100)) // no-op
+	assert.Equal(t, 3, buf.Len())
+	assert.Equal(t, 5, buf.Free())
+	assert.NoError(t, buf.Close())
+
+	buf, err = OpenMmapRing[int](path, 8, intFixedCodec{})
+	assert.NoError(t, err)
+	defer buf.Close()
+	assert.Equal(t, Position(2), buf.FirstPosition())
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, items)
+}
+
+func TestMmapRingReads(t *testing.T) {
+	checkReset(t, newMmapRing(t, 3))
+	checkStats(t, newMmapRing(t, 3))
+	checkAppendPos(t, newMmapRing(t, 3))
+	checkCopyTo(t, newMmapRing(t, 4))
+	checkToSliceN(t, newMmapRing(t, 4))
+	checkIteratorPosition(t, newMmapRing(t, 4))
+
+	buf := newMmapRing(t, 4)
+	for i := 0; i < 6; i++ {
+		if i >= 4 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+	}
+	checkToSliceRange(t, buf)
+	checkGet(t, buf, 2, 6)
+}
+
+func TestMmapRingMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ring")
+	buf, err := OpenMmapRing[uint32](path, 3, uint32Codec{})
+	assert.NoError(t, err)
+	assert.NoError(t, buf.Close())
+
+	_, err = OpenMmapRing[uint32](path, 4, uint32Codec{})
+	assert.True(t, errors.Is(err, ErrInvalidState))
+}
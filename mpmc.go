@@ -0,0 +1,315 @@
+package ringbuf
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+func NewMPMCBuf[F any](size int) *MPMCBuf[F] {
+	b := &MPMCBuf[F]{
+		seqs:  make([]uint64, size),
+		items: make([]F, size),
+	}
+	b.ResetAt(0)
+	return b
+}
+
+// MPMCBuf is a lock-free Buffer that any number of goroutines may Append to
+// and Drop from. Each slot carries a sequence number telling whether it is
+// free, being written or published, as in Dmitry Vyukov's bounded queue.
+//
+// Reads only see published items and must not overlap with a concurrent Drop
+// of the positions being read. Clone, Reset and ResetAt must not run
+// concurrently with any other method.
+//
+// head and tail are on separate cache lines so that the producers claiming
+// slots do not slow down the consumers dropping them. The sequence numbers
+// are kept apart from the items so that they stay 64-bit aligned whatever
+// the size of F.
+type MPMCBuf[F any] struct {
+	head   paddedCounter // number of dropped items
+	tail   paddedCounter // number of claimed items
+	stats  counters
+	seqs   []uint64 // sequence number of each slot
+	items  []F
+	origin Position
+}
+
+func (b *MPMCBuf[F]) Drop(drop Position) error {
+	tail := b.tail.load()
+	n := int(drop - b.position(tail)) // drop - next
+	if 0 <= n {
+		return b.stats.fail(errOutOfRange(drop, b.FirstPosition(), b.position(tail)))
+	}
+	target := tail + uint64(n+1)
+	size := uint64(len(b.seqs))
+	for {
+		head := b.head.load()
+		if target <= head || head+size < target {
+			return nil
+		}
+		i := head % size
+		switch seq := atomic.LoadUint64(&b.seqs[i]); {
+		case seq == head+1:
+			if b.head.cas(head, head+1) {
+				var zero F
+				b.items[i] = zero
+				atomic.StoreUint64(&b.seqs[i], head+size)
+				b.stats.dropped(1)
+			}
+		case seq < head+1:
+			runtime.Gosched() // claimed but not yet published
+		}
+	}
+}
+
+func (b *MPMCBuf[F]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *MPMCBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *MPMCBuf[F]) AppendPos(item F) (Position, error) {
+	size := uint64(len(b.seqs))
+	if size == 0 {
+		return b.NextPosition(), b.stats.fail(errOverflow(b.FirstPosition(), 0))
+	}
+	for {
+		tail := b.tail.load()
+		i := tail % size
+		switch seq := atomic.LoadUint64(&b.seqs[i]); {
+		case seq == tail:
+			if b.tail.cas(tail, tail+1) {
+				b.items[i] = item
+				atomic.StoreUint64(&b.seqs[i], tail+1)
+				b.stats.appended(1, b.Len())
+				return b.position(tail), nil
+			}
+		case seq < tail:
+			return b.position(tail), b.stats.fail(errOverflow(b.FirstPosition(), len(b.seqs)))
+		}
+	}
+}
+
+// Claim reserves up to n slots for the caller to fill before calling Publish,
+// so that several producers can write their batches in parallel. It returns
+// the position of the first slot and the slots, which are fewer than n at the
+// end of the backing array or if the buffer has fewer free slots, or
+// ErrBufferOverflow if it has none. Until published, the slots hold back the
+// readers and Drop.
+func (b *MPMCBuf[F]) Claim(n int) (Position, []F, error) {
+	size := uint64(len(b.seqs))
+	for {
+		tail := b.tail.load()
+		if n <= 0 {
+			return b.position(tail), nil, nil
+		}
+		if size == 0 {
+			return b.position(tail), nil, b.stats.fail(errOverflow(b.FirstPosition(), 0))
+		}
+		i := tail % size
+		m := uint64(n)
+		if size-i < m {
+			m = size - i
+		}
+		free := uint64(0)
+		for free < m && atomic.LoadUint64(&b.seqs[i+free]) == tail+free {
+			free++
+		}
+		if free == 0 {
+			if atomic.LoadUint64(&b.seqs[i]) < tail {
+				return b.position(tail), nil, b.stats.fail(errOverflow(b.FirstPosition(), len(b.seqs)))
+			}
+			continue // claimed by another producer
+		}
+		if b.tail.cas(tail, tail+free) {
+			return b.position(tail), b.items[i : i+free : i+free], nil
+		}
+	}
+}
+
+// Publish makes the n slots claimed from pos visible to the readers. Readers
+// see the items in position order, up to the first slot not published yet.
+func (b *MPMCBuf[F]) Publish(pos Position, n int) error {
+	tail := b.tail.load()
+	back := int(b.position(tail) - pos) // next - pos
+	if n < 0 || back < n || tail < uint64(back) {
+		return fmt.Errorf("%w: publishing %v slots from %v not claimed", ErrInvalidState, n, pos)
+	}
+	size := uint64(len(b.seqs))
+	c := tail - uint64(back)
+	for k := c; k < c+uint64(n); k++ {
+		if !atomic.CompareAndSwapUint64(&b.seqs[k%size], k, k+1) {
+			return fmt.Errorf("%w: slot %v not claimed", ErrInvalidState, b.position(k))
+		}
+	}
+	b.stats.appended(n, b.Len())
+	return nil
+}
+
+func (b *MPMCBuf[F]) Iterator(start Position) (*Iterator[F], error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	return NewIteratorAt[F](start, items), nil
+}
+
+func (b *MPMCBuf[F]) ToSlice(start Position) ([]F, error) {
+	return b.ToSliceN(start, len(b.seqs))
+}
+
+func (b *MPMCBuf[F]) ToSliceRange(start, end Position) ([]F, error) {
+	n := int(end - start)
+	items, err := b.ToSliceN(start, n)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || len(items) < n {
+		low, high := b.Bounds()
+		return nil, b.stats.fail(errOutOfRange(end, low, high))
+	}
+	return items, nil
+}
+
+func (b *MPMCBuf[F]) ToSliceN(start Position, max int) ([]F, error) {
+	begin, end, err := b.published(start)
+	if err != nil {
+		return nil, err
+	}
+	if max < 0 {
+		max = 0
+	}
+	if uint64(max) < end-begin {
+		end = begin + uint64(max)
+	}
+	ret := make([]F, 0, end-begin)
+	size := uint64(len(b.seqs))
+	for c := begin; c < end; c++ {
+		ret = append(ret, b.items[c%size])
+	}
+	return ret, nil
+}
+
+func (b *MPMCBuf[F]) CopyTo(dst []F, start Position) (int, error) {
+	begin, end, err := b.published(start)
+	if err != nil {
+		return 0, err
+	}
+	size := uint64(len(b.seqs))
+	n := 0
+	for c := begin; c < end && n < len(dst); c++ {
+		dst[n] = b.items[c%size]
+		n++
+	}
+	return n, nil
+}
+
+func (b *MPMCBuf[F]) Get(pos Position) (F, error) {
+	var zero F
+	begin, end, err := b.published(pos)
+	if err != nil {
+		return zero, err
+	}
+	if begin == end {
+		low, high := b.Bounds()
+		return zero, b.stats.fail(errOutOfRange(pos, low, high))
+	}
+	return b.items[begin%uint64(len(b.seqs))], nil
+}
+
+// published returns the counts of the items from start up to the first item
+// not published yet.
+func (b *MPMCBuf[F]) published(start Position) (uint64, uint64, error) {
+	head, tail := b.head.load(), b.tail.load()
+	n := int(start - b.position(head))
+	if n < 0 || int(tail-head) < n {
+		return 0, 0, b.stats.fail(errOutOfRange(start, b.position(head), b.position(tail)))
+	}
+	size := uint64(len(b.seqs))
+	begin := head + uint64(n)
+	end := begin
+	for end < tail && atomic.LoadUint64(&b.seqs[end%size]) == end+1 {
+		end++
+	}
+	return begin, end, nil
+}
+
+func (b *MPMCBuf[F]) position(count uint64) Position {
+	return b.origin + Position(count)
+}
+
+func (b *MPMCBuf[F]) Bounds() (Position, Position) {
+	return b.FirstPosition(), b.NextPosition()
+}
+
+func (b *MPMCBuf[F]) FirstPosition() Position {
+	return b.position(b.head.load())
+}
+
+func (b *MPMCBuf[F]) NextPosition() Position {
+	return b.position(b.tail.load())
+}
+
+func (b *MPMCBuf[F]) Clone() Buffer[F] {
+	seqs := make([]uint64, len(b.seqs))
+	copy(seqs, b.seqs)
+	items := make([]F, len(b.items))
+	copy(items, b.items)
+	return &MPMCBuf[F]{
+		head:   paddedCounter{v: b.head.v},
+		tail:   paddedCounter{v: b.tail.v},
+		stats:  b.stats.clone(),
+		seqs:   seqs,
+		items:  items,
+		origin: b.origin,
+	}
+}
+
+func (b *MPMCBuf[F]) Len() int {
+	return int(b.tail.load() - b.head.load())
+}
+
+func (b *MPMCBuf[F]) Cap() int {
+	return len(b.seqs)
+}
+
+func (b *MPMCBuf[F]) MemoryUsage() int64 {
+	return int64(len(b.seqs)) * (8 + sizeOf[F]())
+}
+
+func (b *MPMCBuf[F]) Free() int {
+	return b.Cap() - b.Len()
+}
+
+func (b *MPMCBuf[F]) IsEmpty() bool {
+	return b.Len() <= 0
+}
+
+func (b *MPMCBuf[F]) IsFull() bool {
+	return b.Free() <= 0
+}
+
+func (b *MPMCBuf[F]) Stats() Stats {
+	return b.stats.stats(b.Len())
+}
+
+func (b *MPMCBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *MPMCBuf[F]) ResetAt(start Position) {
+	b.stats.dropped(b.Len())
+	var zero F
+	for i := range b.seqs {
+		b.seqs[i] = uint64(i)
+		b.items[i] = zero
+	}
+	b.origin = start
+	b.head.store(0)
+	b.tail.store(0)
+}
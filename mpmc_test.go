@@ -0,0 +1,235 @@
+package ringbuf
+
+import (
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMPMCBuf(t *testing.T) {
+	buf := NewMPMCBuf[int](3)
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.Append(1))
+	assert.NoError(t, buf.Append(2))
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(3))
+	items, err = buf.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+
+	_, err = buf.ToSlice(0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.NoError(t, buf.Drop(-1)) // no-op
+	assert.Equal(t, Position(1), buf.FirstPosition())
+	assert.True(t, errors.Is(buf.Drop(4), ErrOutOfRange))
+}
+
+func TestMPMCBufReads(t *testing.T) {
+	checkBounds(t, NewMPMCBuf[Item](3))
+	checkLen(t, NewMPMCBuf[Item](3), 0, 3)
+	checkPositions(t, NewMPMCBuf[Item](3))
+	checkReset(t, NewMPMCBuf[int](3))
+	checkStats(t, NewMPMCBuf[int](3))
+	checkAppendPos(t, NewMPMCBuf[int](3))
+	checkCopyTo(t, NewMPMCBuf[int](4))
+	checkToSliceN(t, NewMPMCBuf[int](4))
+	checkIteratorPosition(t, NewMPMCBuf[int](4))
+
+	buf := NewMPMCBuf[int](4)
+	for i := 0; i < 6; i++ {
+		if i >= 4 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+	}
+	checkToSliceRange(t, buf)
+	checkGet(t, buf, 2, 6)
+}
+
+func TestMPMCBufClone(t *testing.T) {
+	buf := NewMPMCBuf[int](3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	clone := buf.Clone()
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3))
+	assert.True(t, errors.Is(clone.Append(3), ErrBufferOverflow))
+
+	items, err := clone.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, items)
+	items, err = buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, items)
+}
+
+func TestMPMCBufConcurrent(t *testing.T) {
+	const producers, n = 4, 2000
+	buf := NewMPMCBuf[int](16)
+	wg := sync.WaitGroup{}
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; {
+				if buf.Append(p*n+i) == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	var received []int
+	for len(received) < producers*n {
+		start := buf.FirstPosition()
+		item, err := buf.Get(start)
+		if err != nil {
+			runtime.Gosched()
+			continue
+		}
+		received = append(received, item)
+		assert.NoError(t, buf.Drop(start))
+	}
+	wg.Wait()
+	sort.Ints(received)
+	for i, item := range received {
+		if !assert.Equal(t, i, item) {
+			break
+		}
+	}
+}
+
+func TestMPMCBufConcurrentDrop(t *testing.T) {
+	const n = 1000
+	buf := NewMPMCBuf[int](n)
+	for i := 0; i < n; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	wg := sync.WaitGroup{}
+	for c := 0; c < 4; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			for pos := Position(c); pos < n; pos += 4 {
+				assert.NoError(t, buf.Drop(pos))
+			}
+		}(c)
+	}
+	wg.Wait()
+	assert.Equal(t, 0, buf.Len())
+	assert.Equal(t, Position(n), buf.FirstPosition())
+	for i := 0; i < n; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+}
+
+func TestMPMCBufClaim(t *testing.T) {
+	buf := NewMPMCBuf[int](4)
+	assert.NoError(t, buf.Append(0))
+	pos, slots, err := buf.Claim(2)
+	assert.NoError(t, err)
+	assert.Equal(t, Position(1), pos)
+	assert.Equal(t, 2, len(slots))
+	slots[0], slots[1] = 1, 2
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0}, items) // not published yet
+
+	assert.NoError(t, buf.Publish(pos, len(slots)))
+	items, err = buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, items)
+	assert.True(t, errors.Is(buf.Publish(pos, 1), ErrInvalidState))
+	assert.True(t, errors.Is(buf.Publish(pos, 3), ErrInvalidState))
+
+	assert.NoError(t, buf.Drop(1))
+	pos, slots, err = buf.Claim(3) // up to the end of the array
+	assert.NoError(t, err)
+	assert.Equal(t, Position(3), pos)
+	assert.Equal(t, 1, len(slots))
+	assert.NoError(t, buf.Publish(pos, 1))
+	pos, slots, err = buf.Claim(3) // up to the free slots
+	assert.NoError(t, err)
+	assert.Equal(t, Position(4), pos)
+	assert.Equal(t, 2, len(slots))
+	assert.NoError(t, buf.Publish(pos, 2))
+	_, _, err = buf.Claim(1)
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, uint64(6), buf.Stats().Appends)
+}
+
+func TestMPMCBufEmpty(t *testing.T) {
+	buf := NewMPMCBuf[int](0)
+	pos, err := buf.AppendPos(1)
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, Position(0), pos)
+	_, slots, err := buf.Claim(1)
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Nil(t, slots)
+	assert.True(t, errors.Is(buf.Drop(0), ErrOutOfRange))
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestMPMCBufClaimConcurrent(t *testing.T) {
+	const producers, n, batch = 4, 500, 3
+	buf := NewMPMCBuf[int](16)
+	wg := sync.WaitGroup{}
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; {
+				pos, slots, err := buf.Claim(batch)
+				if err != nil {
+					runtime.Gosched()
+					continue
+				}
+				for k := range slots {
+					slots[k] = int(pos) + k
+				}
+				runtime.Gosched()
+				assert.NoError(t, buf.Publish(pos, len(slots)))
+				i += len(slots)
+			}
+		}(p)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	next := Position(0)
+	for {
+		items, err := buf.ToSlice(next)
+		assert.NoError(t, err)
+		for _, item := range items {
+			assert.Equal(t, int(next), item)
+			next++
+		}
+		if len(items) > 0 {
+			assert.NoError(t, buf.Drop(next-1))
+		}
+		select {
+		case <-done:
+			if buf.Len() == 0 {
+				assert.True(t, producers*n <= int(next))
+				return
+			}
+		default:
+			runtime.Gosched()
+		}
+	}
+}
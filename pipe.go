@@ -0,0 +1,97 @@
+package ringbuf
+
+import "context"
+
+// Pipe returns the two ends of a bounded pipe of size items backed by a
+// SyncBuf. The producer blocks while size items are not acknowledged by the
+// consumer.
+func Pipe[F any](size int) (*Producer[F], *Consumer[F]) {
+	buf := NewSyncBuf[F](NewRingBuf[F](size))
+	return &Producer[F]{buf: buf}, &Consumer[F]{buf: buf, cur: -1}
+}
+
+// Producer is the writing end of a Pipe.
+type Producer[F any] struct {
+	buf *SyncBuf[F]
+}
+
+// Append appends item, blocking while the pipe is full until ctx is done. It
+// returns ErrSealed after Close.
+func (p *Producer[F]) Append(ctx context.Context, item F) error {
+	return p.buf.AppendWait(ctx, item)
+}
+
+// Close makes the consumer stop once it has scanned the remaining items.
+func (p *Producer[F]) Close() {
+	p.buf.Seal()
+}
+
+// Consumer is the reading end of a Pipe. It implements Scanner.
+type Consumer[F any] struct {
+	buf  *SyncBuf[F]
+	pos  Position // position of the next item to scan
+	cur  Position // position of the current item
+	item F
+	err  error
+}
+
+// Scan advances to the next item, blocking until it is appended. It returns
+// false once the producer is closed and every item is scanned.
+func (c *Consumer[F]) Scan() bool {
+	return c.ScanContext(context.Background())
+}
+
+// ScanContext is Scan returning false when ctx is done, after which Err
+// returns the error of ctx.
+func (c *Consumer[F]) ScanContext(ctx context.Context) bool {
+	if c.err != nil {
+		return false
+	}
+	b := c.buf
+	for {
+		b.mu.Lock()
+		if c.pos-b.buf.NextPosition() < 0 {
+			item, err := b.buf.Get(c.pos)
+			b.mu.Unlock()
+			if err != nil {
+				c.err = err
+				return false
+			}
+			c.item, c.cur = item, c.pos
+			c.pos++
+			return true
+		}
+		if b.sealed {
+			b.mu.Unlock()
+			return false
+		}
+		wait := b.changed()
+		b.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			c.err = ctx.Err()
+			return false
+		case <-wait:
+		}
+	}
+}
+
+func (c *Consumer[F]) Item() F {
+	return c.item
+}
+
+func (c *Consumer[F]) Position() Position {
+	return c.cur
+}
+
+// Ack releases the items up to the current one, making room for the
+// producer.
+func (c *Consumer[F]) Ack() error {
+	return c.buf.Drop(c.cur)
+}
+
+// Err returns the error that stopped ScanContext, or nil if the pipe was
+// closed.
+func (c *Consumer[F]) Err() error {
+	return c.err
+}
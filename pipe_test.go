@@ -0,0 +1,61 @@
+package ringbuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipe(t *testing.T) {
+	p, c := Pipe[int](2)
+	ctx := context.Background()
+	go func() {
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, p.Append(ctx, i))
+		}
+		p.Close()
+	}()
+	var items []int
+	for c.Scan() {
+		assert.Equal(t, Position(len(items)), c.Position())
+		items = append(items, c.Item())
+		assert.NoError(t, c.Ack())
+	}
+	assert.NoError(t, c.Err())
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, items)
+	assert.True(t, errors.Is(p.Append(ctx, 10), ErrSealed))
+	assert.False(t, c.Scan())
+}
+
+func TestPipeBounded(t *testing.T) {
+	p, c := Pipe[int](1)
+	assert.NoError(t, c.Ack()) // nothing to acknowledge yet
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, p.Append(ctx, 0))
+	assert.Equal(t, context.DeadlineExceeded, p.Append(ctx, 1))
+
+	assert.True(t, c.Scan())
+	assert.Equal(t, 0, c.Item())
+	assert.NoError(t, c.Ack())
+	assert.NoError(t, p.Append(context.Background(), 1))
+
+	assert.True(t, c.Scan())
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.False(t, c.ScanContext(ctx))
+	assert.Equal(t, context.DeadlineExceeded, c.Err())
+}
+
+func TestPipeScanner(t *testing.T) {
+	p, c := Pipe[int](4)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, p.Append(context.Background(), i))
+	}
+	p.Close()
+	odd := Filter[int](c, func(i int) bool { return i%2 == 1 })
+	assert.Equal(t, []int{1, 3}, scanAll(odd))
+}
@@ -0,0 +1,72 @@
+package ringbuf
+
+// ReadOnly returns a Reader of buf that cannot be converted back to buf, so
+// that code given the Reader is unable to append to or drop from buf. The
+// slices it returns alias buf as far as those of buf do.
+func ReadOnly[F any](buf Buffer[F]) Reader[F] {
+	return readOnly[F]{buf: buf}
+}
+
+type readOnly[F any] struct {
+	buf Buffer[F]
+}
+
+func (r readOnly[F]) Iterator(start Position) (*Iterator[F], error) {
+	return r.buf.Iterator(start)
+}
+
+func (r readOnly[F]) ToSlice(start Position) ([]F, error) {
+	return r.buf.ToSlice(start)
+}
+
+func (r readOnly[F]) Bounds() (Position, Position) {
+	return r.buf.Bounds()
+}
+
+func (r readOnly[F]) Len() int {
+	return r.buf.Len()
+}
+
+func (r readOnly[F]) Cap() int {
+	return r.buf.Cap()
+}
+
+func (r readOnly[F]) Free() int {
+	return r.buf.Free()
+}
+
+func (r readOnly[F]) IsEmpty() bool {
+	return r.buf.IsEmpty()
+}
+
+func (r readOnly[F]) IsFull() bool {
+	return r.buf.IsFull()
+}
+
+func (r readOnly[F]) Get(pos Position) (F, error) {
+	return r.buf.Get(pos)
+}
+
+func (r readOnly[F]) ToSliceRange(start, end Position) ([]F, error) {
+	return r.buf.ToSliceRange(start, end)
+}
+
+func (r readOnly[F]) CopyTo(dst []F, start Position) (int, error) {
+	return r.buf.CopyTo(dst, start)
+}
+
+func (r readOnly[F]) FirstPosition() Position {
+	return r.buf.FirstPosition()
+}
+
+func (r readOnly[F]) NextPosition() Position {
+	return r.buf.NextPosition()
+}
+
+func (r readOnly[F]) ToSliceN(start Position, max int) ([]F, error) {
+	return r.buf.ToSliceN(start, max)
+}
+
+func (r readOnly[F]) Stats() Stats {
+	return r.buf.Stats()
+}
@@ -0,0 +1,50 @@
+package ringbuf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadOnly(t *testing.T) {
+	buf := NewRingBuf[int](3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	r := ReadOnly[int](buf)
+	_, ok := r.(Dropper)
+	assert.False(t, ok)
+	_, ok = r.(Appender[int])
+	assert.False(t, ok)
+
+	items, err := r.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, items)
+	assert.NoError(t, buf.Drop(0))
+	assert.Equal(t, Position(1), r.FirstPosition())
+	assert.Equal(t, Position(3), r.NextPosition())
+	assert.Equal(t, 2, r.Len())
+	assert.Equal(t, 3, r.Cap())
+	assert.Equal(t, 1, r.Free())
+	item, err := r.Get(2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, item)
+	assert.Equal(t, buf.Stats(), r.Stats())
+
+	iter, err := r.Iterator(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, iter.ToSlice())
+	items, err = r.ToSliceN(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, items)
+	items, err = r.ToSliceRange(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, items)
+	dst := make([]int, 3)
+	n, err := r.CopyTo(dst, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	low, high := r.Bounds()
+	assert.Equal(t, Position(0), low)
+	assert.Equal(t, Position(3), high)
+}
@@ -0,0 +1,78 @@
+package ringbuf
+
+import (
+	"time"
+)
+
+// Retained is the view of a TimedBuf given to a RetentionPolicy.
+type Retained[F any] interface {
+	FirstPosition() Position
+	NextPosition() Position
+	Get(pos Position) (F, error)
+	Time(pos Position) (time.Time, error)
+}
+
+// RetentionPolicy decides which items a TimedBuf keeps.
+type RetentionPolicy[F any] interface {
+	// Keep returns the first position to keep at now. The items before it
+	// are dropped.
+	Keep(r Retained[F], now time.Time) Position
+}
+
+// RetentionFunc adapts a function to a RetentionPolicy.
+type RetentionFunc[F any] func(r Retained[F], now time.Time) Position
+
+func (f RetentionFunc[F]) Keep(r Retained[F], now time.Time) Position {
+	return f(r, now)
+}
+
+// KeepN keeps the n newest items.
+func KeepN[F any](n int) RetentionPolicy[F] {
+	return RetentionFunc[F](func(r Retained[F], _ time.Time) Position {
+		return r.NextPosition() - Position(n)
+	})
+}
+
+// KeepFor keeps the items appended within maxAge.
+func KeepFor[F any](maxAge time.Duration) RetentionPolicy[F] {
+	return RetentionFunc[F](func(r Retained[F], now time.Time) Position {
+		pos, next := r.FirstPosition(), r.NextPosition()
+		for ; pos-next < 0; pos++ { // pos < next
+			if stamp, err := r.Time(pos); err == nil && now.Sub(stamp) <= maxAge {
+				break
+			}
+		}
+		return pos
+	})
+}
+
+// KeepBytes keeps the newest items whose sizes add up to at most max.
+func KeepBytes[F any](max int, size func(item F) int) RetentionPolicy[F] {
+	return RetentionFunc[F](func(r Retained[F], _ time.Time) Position {
+		first, pos := r.FirstPosition(), r.NextPosition()
+		total := 0
+		for ; pos-first > 0; pos-- { // first < pos
+			item, err := r.Get(pos - 1)
+			if err != nil {
+				break
+			}
+			if total += size(item); max < total {
+				break
+			}
+		}
+		return pos
+	})
+}
+
+// Composite keeps only the items kept by every policy.
+func Composite[F any](policies ...RetentionPolicy[F]) RetentionPolicy[F] {
+	return RetentionFunc[F](func(r Retained[F], now time.Time) Position {
+		keep := r.FirstPosition()
+		for _, p := range policies {
+			if pos := p.Keep(r, now); 0 < pos-keep {
+				keep = pos
+			}
+		}
+		return keep
+	})
+}
@@ -0,0 +1,75 @@
+package ringbuf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRetentionBuf(t *testing.T, now *time.Time) *TimedBuf[string] {
+	t.Helper()
+	buf := NewTimedBuf[string](8)
+	buf.ring.now = func() time.Time { return *now }
+	return buf
+}
+
+func TestRetentionKeepN(t *testing.T) {
+	now := time.Unix(0, 0)
+	buf := newRetentionBuf(t, &now)
+	buf.SetRetention(KeepN[string](2))
+	for _, item := range []string{"a", "b", "c"} {
+		assert.NoError(t, buf.Append(item))
+	}
+	items, err := buf.ToSlice(buf.FirstPosition())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, items)
+}
+
+func TestRetentionKeepFor(t *testing.T) {
+	now := time.Unix(0, 0)
+	buf := newRetentionBuf(t, &now)
+	buf.SetRetention(KeepFor[string](time.Minute))
+	for _, item := range []string{"a", "b", "c"} {
+		assert.NoError(t, buf.Append(item))
+		now = now.Add(40 * time.Second)
+	}
+	assert.Equal(t, 2, buf.Len()) // a expired when c was appended
+	assert.Equal(t, 1, buf.Retain())
+	assert.Equal(t, Position(2), buf.FirstPosition())
+	assert.Equal(t, 0, buf.Retain())
+}
+
+func TestRetentionKeepBytes(t *testing.T) {
+	now := time.Unix(0, 0)
+	buf := newRetentionBuf(t, &now)
+	size := func(item string) int { return len(item) }
+	buf.SetRetention(KeepBytes[string](5, size))
+	for _, item := range []string{"aaa", "bb", "c", "dd"} {
+		assert.NoError(t, buf.Append(item))
+	}
+	items, err := buf.ToSlice(buf.FirstPosition())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bb", "c", "dd"}, items)
+}
+
+func TestRetentionComposite(t *testing.T) {
+	now := time.Unix(0, 0)
+	buf := newRetentionBuf(t, &now)
+	buf.SetRetention(Composite[string](KeepN[string](3), KeepFor[string](time.Minute)))
+	for _, item := range []string{"a", "b", "c", "d"} {
+		assert.NoError(t, buf.Append(item))
+	}
+	assert.Equal(t, 3, buf.Len())
+	now = now.Add(time.Hour)
+	assert.NoError(t, buf.Append("e"))
+	items, err := buf.ToSlice(buf.FirstPosition())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"e"}, items)
+
+	clone := buf.Clone().(*TimedBuf[string])
+	for _, item := range []string{"f", "g", "h"} {
+		assert.NoError(t, clone.Append(item))
+	}
+	assert.Equal(t, 3, clone.Len())
+}
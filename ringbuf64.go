@@ -0,0 +1,166 @@
+package ringbuf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// NewRingBuf64 returns an empty RingBuf64 holding size items whose first
+// appended item is at start.
+func NewRingBuf64[F any](size int, start int64, opts ...Option[F]) *RingBuf64[F] {
+	return &RingBuf64[F]{
+		ring: NewRingBufAt[F](size, Position(start), opts...),
+		next: start,
+	}
+}
+
+// RingBuf64 is a RingBuf addressed by 64-bit positions, e.g. the sequence
+// numbers of a stream that outgrows Position. The RingBuf holds the low 32
+// bits of the positions, and those it returns are expanded back from the
+// 64-bit next position, which is exact since less than 1<<31 items are
+// buffered. Like Position, the positions wrap around at the end of their
+// range. It does not implement Buffer, whose positions are Position.
+type RingBuf64[F any] struct {
+	ring *RingBuf[F]
+	next int64 // NextPosition of ring with the upper 32 bits
+}
+
+// expand returns the 64-bit position of pos, which is within 1<<31 of the
+// next position.
+func (b *RingBuf64[F]) expand(pos Position) int64 {
+	return b.next + int64(pos-Position(b.next))
+}
+
+// narrow returns the Position of pos, or false if pos is too far from the
+// next position to be in the buffer.
+func (b *RingBuf64[F]) narrow(pos int64) (Position, bool) {
+	if d := pos - b.next; d < math.MinInt32 || math.MaxInt32 < d {
+		return 0, false
+	}
+	return Position(pos), true
+}
+
+// update expands the next position after a mutation of the ring.
+func (b *RingBuf64[F]) update() {
+	b.next = b.expand(b.ring.NextPosition())
+}
+
+// translate translates an error of the ring about pos to 64-bit positions.
+func (b *RingBuf64[F]) translate(err error, pos int64) error {
+	switch {
+	case errors.Is(err, ErrOutOfRange):
+		low, high := b.Bounds()
+		return fmt.Errorf("%w: %v not in range [%v, %v)", ErrOutOfRange, pos, low, high)
+	case errors.Is(err, ErrBufferOverflow):
+		return fmt.Errorf("%w: %v items from %v", ErrBufferOverflow, b.Cap(), b.FirstPosition())
+	}
+	return err
+}
+
+func (b *RingBuf64[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+// AppendPos appends item and returns its position. On failure it returns the
+// position the item would have had.
+func (b *RingBuf64[F]) AppendPos(item F) (int64, error) {
+	pos := b.next
+	_, err := b.ring.AppendPos(item)
+	b.update()
+	return pos, b.translate(err, pos)
+}
+
+// AppendAll appends items like RingBuf.AppendAll and returns the position of
+// the first one.
+func (b *RingBuf64[F]) AppendAll(items []F) (int64, error) {
+	pos := b.next
+	_, err := b.ring.AppendAll(items)
+	b.update()
+	return pos, b.translate(err, pos)
+}
+
+// Drop drops the items up to and including drop like RingBuf.Drop.
+func (b *RingBuf64[F]) Drop(drop int64) error {
+	p, ok := b.narrow(drop)
+	if !ok {
+		return b.translate(ErrOutOfRange, drop)
+	}
+	return b.translate(b.ring.Drop(p), drop)
+}
+
+func (b *RingBuf64[F]) Get(pos int64) (F, error) {
+	p, ok := b.narrow(pos)
+	if !ok {
+		var zero F
+		return zero, b.translate(ErrOutOfRange, pos)
+	}
+	item, err := b.ring.Get(p)
+	return item, b.translate(err, pos)
+}
+
+func (b *RingBuf64[F]) ToSlice(start int64) ([]F, error) {
+	p, ok := b.narrow(start)
+	if !ok {
+		return nil, b.translate(ErrOutOfRange, start)
+	}
+	items, err := b.ring.ToSlice(p)
+	return items, b.translate(err, start)
+}
+
+func (b *RingBuf64[F]) ToSliceRange(start, end int64) ([]F, error) {
+	p, ok := b.narrow(start)
+	if !ok || end < start || math.MaxInt32 < end-start {
+		return nil, b.translate(ErrOutOfRange, start)
+	}
+	items, err := b.ring.ToSliceRange(p, p+Position(end-start))
+	return items, b.translate(err, start)
+}
+
+func (b *RingBuf64[F]) Bounds() (low int64, high int64) {
+	l, h := b.ring.Bounds()
+	return b.expand(l), b.expand(h)
+}
+
+func (b *RingBuf64[F]) FirstPosition() int64 {
+	return b.expand(b.ring.FirstPosition())
+}
+
+func (b *RingBuf64[F]) NextPosition() int64 {
+	return b.next
+}
+
+func (b *RingBuf64[F]) Len() int {
+	return b.ring.Len()
+}
+
+func (b *RingBuf64[F]) Cap() int {
+	return b.ring.Cap()
+}
+
+func (b *RingBuf64[F]) Free() int {
+	return b.ring.Free()
+}
+
+func (b *RingBuf64[F]) IsEmpty() bool {
+	return b.ring.IsEmpty()
+}
+
+func (b *RingBuf64[F]) IsFull() bool {
+	return b.ring.IsFull()
+}
+
+func (b *RingBuf64[F]) Stats() Stats {
+	return b.ring.Stats()
+}
+
+func (b *RingBuf64[F]) Reset() {
+	b.ResetAt(0)
+}
+
+// ResetAt empties the buffer so that the next Append is assigned start.
+func (b *RingBuf64[F]) ResetAt(start int64) {
+	b.ring.ResetAt(Position(start))
+	b.next = start
+}
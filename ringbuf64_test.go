@@ -0,0 +1,64 @@
+package ringbuf
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingBuf64(t *testing.T) {
+	const start = 1<<40 - 2 // crosses a 1<<32 boundary
+	buf := NewRingBuf64[int64](3, start)
+	for i := int64(0); i < 6; i++ {
+		if i >= 3 {
+			assert.NoError(t, buf.Drop(start+i-3))
+		}
+		pos, err := buf.AppendPos(start + i)
+		assert.NoError(t, err)
+		assert.Equal(t, start+i, pos)
+	}
+	assert.Equal(t, int64(start+3), buf.FirstPosition())
+	assert.Equal(t, int64(start+6), buf.NextPosition())
+	low, high := buf.Bounds()
+	assert.Equal(t, int64(start+3), low)
+	assert.Equal(t, int64(start+6), high)
+
+	items, err := buf.ToSlice(start + 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{start + 3, start + 4, start + 5}, items)
+	items, err = buf.ToSliceRange(start+4, start+5)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{start + 4}, items)
+	item, err := buf.Get(start + 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(start+5), item)
+
+	// the same low 32 bits, 1<<32 positions away
+	_, err = buf.Get(start + 5 - 1<<32)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = buf.ToSlice(start + 3 + 1<<32)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.True(t, errors.Is(buf.Drop(start+4+1<<32), ErrOutOfRange))
+	assert.Equal(t, 3, buf.Len())
+
+	_, err = buf.AppendPos(0)
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, "buffer overflow: 3 items from 1099511627777", err.Error())
+}
+
+func TestRingBuf64ResetAt(t *testing.T) {
+	buf := NewRingBuf64[int](2, 0)
+	buf.ResetAt(math.MaxInt64 - 1)
+	pos, err := buf.AppendAll([]int{1, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(math.MaxInt64-1), pos)
+	assert.True(t, buf.IsFull())
+	assert.NoError(t, buf.Drop(math.MaxInt64-1))
+	assert.Equal(t, int64(math.MaxInt64), buf.FirstPosition())
+
+	buf.Reset()
+	assert.True(t, buf.IsEmpty())
+	assert.Equal(t, int64(0), buf.NextPosition())
+}
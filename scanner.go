@@ -0,0 +1,67 @@
+package ringbuf
+
+// Scanner is implemented by Iterator and by the lazy combinators below.
+type Scanner[F any] interface {
+	Scan() bool
+	Item() F
+}
+
+func Filter[F any](it Scanner[F], pred func(F) bool) Scanner[F] {
+	return &filter[F]{it: it, pred: pred}
+}
+
+type filter[F any] struct {
+	it   Scanner[F]
+	pred func(F) bool
+}
+
+func (f *filter[F]) Scan() bool {
+	for f.it.Scan() {
+		if f.pred(f.it.Item()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *filter[F]) Item() F {
+	return f.it.Item()
+}
+
+func Map[F, T any](it Scanner[F], fn func(F) T) Scanner[T] {
+	return &mapper[F, T]{it: it, fn: fn}
+}
+
+type mapper[F, T any] struct {
+	it Scanner[F]
+	fn func(F) T
+}
+
+func (m *mapper[F, T]) Scan() bool {
+	return m.it.Scan()
+}
+
+func (m *mapper[F, T]) Item() T {
+	return m.fn(m.it.Item())
+}
+
+func Take[F any](it Scanner[F], n int) Scanner[F] {
+	return &taker[F]{it: it, n: n}
+}
+
+type taker[F any] struct {
+	it Scanner[F]
+	n  int
+}
+
+func (t *taker[F]) Scan() bool {
+	if t.n <= 0 {
+		return false
+	}
+	t.n--
+	return t.it.Scan()
+}
+
+func (t *taker[F]) Item() F {
+	return t.it.Item()
+}
@@ -0,0 +1,50 @@
+package ringbuf
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	iter := NewIterator[int]([]int{0, 1, 2}, []int{3, 4, 5})
+	even := Filter[int](iter, func(i int) bool { return i%2 == 0 })
+	assert.Equal(t, []int{0, 2, 4}, scanAll(even))
+}
+
+func TestMap(t *testing.T) {
+	iter := NewIterator[int]([]int{0, 1}, []int{2})
+	str := Map[int, string](iter, strconv.Itoa)
+	assert.Equal(t, []string{"0", "1", "2"}, scanAll(str))
+}
+
+func TestTake(t *testing.T) {
+	iter := NewIterator[int]([]int{0, 1}, []int{2, 3})
+	assert.Equal(t, []int{0, 1, 2}, scanAll(Take[int](iter, 3)))
+	assert.True(t, iter.Scan())
+	assert.Equal(t, 3, iter.Item())
+
+	iter = NewIterator[int]([]int{0, 1}, []int{2, 3})
+	assert.Equal(t, []int{0, 1, 2, 3}, scanAll(Take[int](iter, 10)))
+	assert.Empty(t, scanAll(Take[int](NewIterator[int]([]int{0}), 0)))
+}
+
+func TestCombinatorsAreLazy(t *testing.T) {
+	var seen []int
+	iter := NewIterator[int]([]int{0, 1, 2, 3, 4, 5, 6})
+	pipeline := Take[string](Map[int, string](Filter[int](iter, func(i int) bool {
+		seen = append(seen, i)
+		return i%3 == 0
+	}), strconv.Itoa), 2)
+	assert.Equal(t, []string{"0", "3"}, scanAll(pipeline))
+	assert.Equal(t, []int{0, 1, 2, 3}, seen)
+}
+
+func scanAll[F any](s Scanner[F]) []F {
+	var ret []F
+	for s.Scan() {
+		ret = append(ret, s.Item())
+	}
+	return ret
+}
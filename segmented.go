@@ -0,0 +1,261 @@
+package ringbuf
+
+import "unsafe"
+
+// NewSegmentedBuf returns a SegmentedBuf holding up to size items in segments
+// of segmentSize items.
+func NewSegmentedBuf[F any](size, segmentSize int) *SegmentedBuf[F] {
+	return &SegmentedBuf[F]{
+		size:    size,
+		segSize: segmentSize,
+		segs:    make([][]F, (size+segmentSize-1)/segmentSize+1),
+	}
+}
+
+// SegmentedBuf is a Buffer storing its items in fixed-size segments that are
+// allocated by Append on demand and released once Drop has dropped all of
+// their items, so that a mostly empty buffer of a large size takes little
+// memory.
+//
+// A segment is never written again below the next position, so Iterator
+// aliases the segments instead of copying the items.
+//
+// Drop releases a segment only once all of its items are dropped, but the
+// dropped items left in a segment cannot be read: Bounds starts at the first
+// position. Dropping below it is a no-op.
+type SegmentedBuf[F any] struct {
+	stats   counters
+	size    int
+	segSize int
+	segs    [][]F  // ring of segments, nil if not allocated
+	head    uint64 // number of dropped items
+	tail    uint64 // number of appended items
+	origin  Position
+}
+
+// segment returns the segment slot of the k-th appended item.
+func (b *SegmentedBuf[F]) segment(k uint64) *[]F {
+	return &b.segs[(k/uint64(b.segSize))%uint64(len(b.segs))]
+}
+
+func (b *SegmentedBuf[F]) Drop(drop Position) error {
+	n := int(drop - b.position(b.tail)) // drop - next
+	if 0 <= n {
+		return b.stats.fail(errOutOfRange(drop, b.FirstPosition(), b.NextPosition()))
+	}
+	target := b.tail + uint64(n+1)
+	if target <= b.head || b.tail < target { // at or below the first position
+		return nil
+	}
+	size := uint64(b.segSize)
+	for k := b.head / size; k < target/size; k++ {
+		*b.segment(k * size) = nil
+	}
+	b.stats.dropped(int(target - b.head))
+	b.head = target
+	return nil
+}
+
+func (b *SegmentedBuf[F]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *SegmentedBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *SegmentedBuf[F]) AppendPos(item F) (Position, error) {
+	pos := b.NextPosition()
+	if uint64(b.size) <= b.tail-b.head {
+		return pos, b.stats.fail(errOverflow(b.FirstPosition(), b.size))
+	}
+	seg := b.segment(b.tail)
+	if *seg == nil {
+		*seg = make([]F, b.segSize)
+	}
+	(*seg)[b.tail%uint64(b.segSize)] = item
+	b.tail++
+	b.stats.appended(1, b.Len())
+	return pos, nil
+}
+
+// views returns up to max items from start as subslices of the segments.
+func (b *SegmentedBuf[F]) views(start Position, max int) ([][]F, error) {
+	n := int(start - b.FirstPosition())
+	if n < 0 || b.Len() < n {
+		return nil, b.stats.fail(errOutOfRange(start, b.FirstPosition(), b.NextPosition()))
+	}
+	begin, end := b.head+uint64(n), b.tail
+	if max < 0 {
+		max = 0
+	}
+	if uint64(max) < end-begin {
+		end = begin + uint64(max)
+	}
+	var ss [][]F
+	for k := begin; k < end; {
+		i := int(k % uint64(b.segSize))
+		j := b.segSize
+		if rest := end - k; rest < uint64(j-i) {
+			j = i + int(rest)
+		}
+		ss = append(ss, (*b.segment(k))[i:j:j])
+		k += uint64(j - i)
+	}
+	return ss, nil
+}
+
+func (b *SegmentedBuf[F]) copy(ss [][]F) []F {
+	n := 0
+	for _, s := range ss {
+		n += len(s)
+	}
+	ret := make([]F, 0, n)
+	for _, s := range ss {
+		ret = append(ret, s...)
+	}
+	return ret
+}
+
+func (b *SegmentedBuf[F]) Iterator(start Position) (*Iterator[F], error) {
+	ss, err := b.views(start, b.Len())
+	if err != nil {
+		return nil, err
+	}
+	return NewIteratorAt[F](start, ss...), nil
+}
+
+func (b *SegmentedBuf[F]) ToSlice(start Position) ([]F, error) {
+	ss, err := b.views(start, b.Len())
+	if err != nil {
+		return nil, err
+	}
+	return b.copy(ss), nil
+}
+
+func (b *SegmentedBuf[F]) ToSliceRange(start, end Position) ([]F, error) {
+	n := int(end - start)
+	ss, err := b.views(start, n)
+	if err != nil {
+		return nil, err
+	}
+	items := b.copy(ss)
+	if n < 0 || len(items) < n {
+		return nil, b.stats.fail(errOutOfRange(end, b.FirstPosition(), b.NextPosition()))
+	}
+	return items, nil
+}
+
+func (b *SegmentedBuf[F]) ToSliceN(start Position, max int) ([]F, error) {
+	ss, err := b.views(start, max)
+	if err != nil {
+		return nil, err
+	}
+	return b.copy(ss), nil
+}
+
+func (b *SegmentedBuf[F]) CopyTo(dst []F, start Position) (int, error) {
+	ss, err := b.views(start, len(dst))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, s := range ss {
+		n += copy(dst[n:], s)
+	}
+	return n, nil
+}
+
+func (b *SegmentedBuf[F]) Get(pos Position) (F, error) {
+	n := int(pos - b.FirstPosition())
+	if n < 0 || b.Len() <= n {
+		var zero F
+		return zero, b.stats.fail(errOutOfRange(pos, b.FirstPosition(), b.NextPosition()))
+	}
+	k := b.head + uint64(n)
+	return (*b.segment(k))[k%uint64(b.segSize)], nil
+}
+
+func (b *SegmentedBuf[F]) position(count uint64) Position {
+	return b.origin + Position(count)
+}
+
+func (b *SegmentedBuf[F]) Bounds() (Position, Position) {
+	return b.FirstPosition(), b.NextPosition()
+}
+
+func (b *SegmentedBuf[F]) FirstPosition() Position {
+	return b.position(b.head)
+}
+
+func (b *SegmentedBuf[F]) NextPosition() Position {
+	return b.position(b.tail)
+}
+
+func (b *SegmentedBuf[F]) Clone() Buffer[F] {
+	segs := make([][]F, len(b.segs))
+	for i, seg := range b.segs {
+		if seg != nil {
+			segs[i] = make([]F, len(seg))
+			copy(segs[i], seg)
+		}
+	}
+	return &SegmentedBuf[F]{
+		stats:   b.stats.clone(),
+		size:    b.size,
+		segSize: b.segSize,
+		segs:    segs,
+		head:    b.head,
+		tail:    b.tail,
+		origin:  b.origin,
+	}
+}
+
+func (b *SegmentedBuf[F]) Len() int {
+	return int(b.tail - b.head)
+}
+
+func (b *SegmentedBuf[F]) Cap() int {
+	return b.size
+}
+
+// MemoryUsage counts the allocated segments only.
+func (b *SegmentedBuf[F]) MemoryUsage() int64 {
+	var seg []F
+	n := int64(len(b.segs)) * int64(unsafe.Sizeof(seg))
+	for _, seg := range b.segs {
+		n += int64(len(seg)) * sizeOf[F]()
+	}
+	return n
+}
+
+func (b *SegmentedBuf[F]) Free() int {
+	return b.size - b.Len()
+}
+
+func (b *SegmentedBuf[F]) IsEmpty() bool {
+	return b.Len() <= 0
+}
+
+func (b *SegmentedBuf[F]) IsFull() bool {
+	return b.Free() <= 0
+}
+
+func (b *SegmentedBuf[F]) Stats() Stats {
+	return b.stats.stats(b.Len())
+}
+
+func (b *SegmentedBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *SegmentedBuf[F]) ResetAt(start Position) {
+	b.stats.dropped(b.Len())
+	for i := range b.segs {
+		b.segs[i] = nil
+	}
+	b.origin = start
+	b.head = 0
+	b.tail = 0
+}
@@ -0,0 +1,126 @@
+package ringbuf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentedBuf(t *testing.T) {
+	buf := NewSegmentedBuf[int](3, 2)
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.Append(1))
+	assert.NoError(t, buf.Append(2))
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(3))
+	items, err = buf.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+
+	_, err = buf.ToSlice(0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.NoError(t, buf.Drop(-1)) // no-op
+	assert.Equal(t, Position(1), buf.FirstPosition())
+	assert.True(t, errors.Is(buf.Drop(4), ErrOutOfRange))
+}
+
+func TestSegmentedBufStaleDrop(t *testing.T) {
+	buf := NewSegmentedBuf[int](8, 2)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Drop(-100)) // no-op
+	assert.Equal(t, 3, buf.Len())
+	assert.Equal(t, Position(2), buf.FirstPosition())
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, items)
+}
+
+func TestSegmentedBufReads(t *testing.T) {
+	checkBounds(t, NewSegmentedBuf[Item](3, 2))
+	checkLen(t, NewSegmentedBuf[Item](3, 2), 0, 3)
+	checkPositions(t, NewSegmentedBuf[Item](3, 2))
+	checkReset(t, NewSegmentedBuf[int](3, 2))
+	checkStats(t, NewSegmentedBuf[int](3, 2))
+	checkAppendPos(t, NewSegmentedBuf[int](3, 2))
+	checkCopyTo(t, NewSegmentedBuf[int](4, 3))
+	checkToSliceN(t, NewSegmentedBuf[int](4, 3))
+	checkIteratorPosition(t, NewSegmentedBuf[int](4, 3))
+
+	buf := NewSegmentedBuf[int](4, 3)
+	for i := 0; i < 6; i++ {
+		if i >= 4 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+	}
+	checkToSliceRange(t, buf)
+	checkGet(t, buf, 2, 6)
+}
+
+func TestSegmentedBufRelease(t *testing.T) {
+	allocated := func(buf *SegmentedBuf[int]) int {
+		n := 0
+		for _, seg := range buf.segs {
+			if seg != nil {
+				n++
+			}
+		}
+		return n
+	}
+	buf := NewSegmentedBuf[int](1000, 10)
+	assert.Equal(t, 0, allocated(buf))
+	for i := 0; i < 25; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, 3, allocated(buf))
+
+	iter, err := buf.Iterator(5)
+	assert.NoError(t, err)
+	assert.NoError(t, buf.Drop(19))
+	assert.Equal(t, 1, allocated(buf))
+	assert.Equal(t, 20, iter.ToSlice()[15])
+
+	for i := 25; i < 1019; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, 100, allocated(buf))
+	items, err := buf.ToSlice(20)
+	assert.NoError(t, err)
+	assert.Equal(t, 999, len(items))
+	for i, item := range items {
+		if !assert.Equal(t, 20+i, item) {
+			break
+		}
+	}
+
+	assert.NoError(t, buf.Drop(1018))
+	assert.Equal(t, 1, allocated(buf))
+	buf.Reset()
+	assert.Equal(t, 0, allocated(buf))
+}
+
+func TestSegmentedBufWrapAround(t *testing.T) {
+	var large int32 = (1 << 31) - 1
+	buf := NewSegmentedBuf[Item](3, 2)
+	buf.ResetAt(large - 1)
+	for pos := large - 1; pos != large+5; pos++ {
+		if buf.Free() == 0 {
+			assert.NoError(t, buf.Drop(pos-3))
+		}
+		assert.NoError(t, buf.Append(pos))
+		items, err := buf.ToSlice(buf.FirstPosition())
+		assert.NoError(t, err)
+		assert.Equal(t, pos, items[len(items)-1])
+	}
+	checkBounds(t, buf.Clone())
+}
@@ -0,0 +1,304 @@
+package ringbuf
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// NewShardedBuf returns a ShardedBuf with n shards of shardSize items each.
+func NewShardedBuf[F any](n, shardSize int) *ShardedBuf[F] {
+	shards := make([]shard[F], n)
+	for i := range shards {
+		shards[i].ring = NewRingBuf[F](shardSize)
+	}
+	return &ShardedBuf[F]{
+		shards: shards,
+	}
+}
+
+// ShardedBuf stores the item at the i-th position in shard i % n, so that
+// producers appending to different shards only contend on one atomic counter.
+// Reads merge the shards back into position order.
+//
+// Drop drops the items from every shard under one mutex, so that concurrent
+// Drops apply in order. Bounds starts at the first position, and dropping
+// below it is a no-op.
+type ShardedBuf[F any] struct {
+	head   uint64 // number of dropped items
+	next   uint64 // number of appended items
+	stats  counters
+	dropMu sync.Mutex
+	shards []shard[F]
+	origin Position
+}
+
+type shard[F any] struct {
+	mu   sync.RWMutex
+	ring *RingBuf[F]
+}
+
+func (b *ShardedBuf[F]) Drop(drop Position) error {
+	b.dropMu.Lock()
+	defer b.dropMu.Unlock()
+	next := atomic.LoadUint64(&b.next)
+	n := int(drop - b.position(next)) // drop - next
+	if 0 <= n {
+		return b.stats.fail(errOutOfRange(drop, b.FirstPosition(), b.position(next)))
+	}
+	target := next + uint64(n+1)
+	head := atomic.LoadUint64(&b.head)
+	if target <= head || next < target { // at or below the first position
+		return nil
+	}
+	size := uint64(len(b.shards))
+	for k := range b.shards {
+		var count uint64 // number of dropped items in shard k
+		if uint64(k) < target {
+			count = (target - uint64(k) + size - 1) / size
+		}
+		s := &b.shards[k]
+		s.mu.Lock()
+		err := s.ring.Drop(Position(count) - 1)
+		s.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&b.head, target)
+	b.stats.dropped(int(target - head))
+	return nil
+}
+
+func (b *ShardedBuf[F]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *ShardedBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *ShardedBuf[F]) AppendPos(item F) (Position, error) {
+	size := uint64(len(b.shards))
+	for {
+		next := atomic.LoadUint64(&b.next)
+		s := &b.shards[next%size]
+		s.mu.Lock()
+		if s.ring.Free() == 0 {
+			s.mu.Unlock()
+			return b.position(next), b.stats.fail(errOverflow(b.FirstPosition(), b.Cap()))
+		}
+		// claiming under the shard lock keeps each shard in position order
+		if !atomic.CompareAndSwapUint64(&b.next, next, next+1) {
+			s.mu.Unlock()
+			continue
+		}
+		err := s.ring.Append(item)
+		s.mu.Unlock()
+		if err == nil {
+			b.stats.appended(1, b.Len())
+		}
+		return b.position(next), err
+	}
+}
+
+func (b *ShardedBuf[F]) Iterator(start Position) (*Iterator[F], error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	return NewIteratorAt[F](start, items), nil
+}
+
+func (b *ShardedBuf[F]) ToSlice(start Position) ([]F, error) {
+	return b.ToSliceN(start, b.Cap())
+}
+
+func (b *ShardedBuf[F]) ToSliceRange(start, end Position) ([]F, error) {
+	n := int(end - start)
+	items, err := b.ToSliceN(start, n)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || len(items) < n {
+		low, high := b.Bounds()
+		return nil, b.stats.fail(errOutOfRange(end, low, high))
+	}
+	return items, nil
+}
+
+func (b *ShardedBuf[F]) ToSliceN(start Position, max int) ([]F, error) {
+	if max < 0 {
+		max = 0
+	}
+	var ret []F
+	err := b.read(start, max, func(item F) {
+		ret = append(ret, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ret == nil {
+		ret = []F{}
+	}
+	return ret, nil
+}
+
+func (b *ShardedBuf[F]) CopyTo(dst []F, start Position) (int, error) {
+	n := 0
+	err := b.read(start, len(dst), func(item F) {
+		dst[n] = item
+		n++
+	})
+	return n, err
+}
+
+func (b *ShardedBuf[F]) Get(pos Position) (F, error) {
+	var ret F
+	found := false
+	err := b.read(pos, 1, func(item F) {
+		ret = item
+		found = true
+	})
+	if err == nil && !found {
+		low, high := b.Bounds()
+		err = b.stats.fail(errOutOfRange(pos, low, high))
+	}
+	return ret, err
+}
+
+// read merges up to max items from start in position order while holding
+// every shard lock.
+func (b *ShardedBuf[F]) read(start Position, max int, fn func(item F)) error {
+	b.rlockAll()
+	defer b.runlockAll()
+	head, next := atomic.LoadUint64(&b.head), atomic.LoadUint64(&b.next)
+	n := int(start - b.position(head))
+	if n < 0 || int(next-head) < n {
+		return b.stats.fail(errOutOfRange(start, b.position(head), b.position(next)))
+	}
+	size := uint64(len(b.shards))
+	begin := head + uint64(n)
+	if uint64(max) < next-begin {
+		next = begin + uint64(max)
+	}
+	for c := begin; c < next; c++ {
+		item, err := b.shards[c%size].ring.Get(Position(c / size))
+		if err != nil {
+			return b.stats.fail(errOutOfRange(start, b.position(head), b.position(next)))
+		}
+		fn(item)
+	}
+	return nil
+}
+
+func (b *ShardedBuf[F]) rlockAll() {
+	for i := range b.shards {
+		b.shards[i].mu.RLock()
+	}
+}
+
+func (b *ShardedBuf[F]) runlockAll() {
+	for i := range b.shards {
+		b.shards[i].mu.RUnlock()
+	}
+}
+
+func (b *ShardedBuf[F]) lockAll() {
+	for i := range b.shards {
+		b.shards[i].mu.Lock()
+	}
+}
+
+func (b *ShardedBuf[F]) unlockAll() {
+	for i := range b.shards {
+		b.shards[i].mu.Unlock()
+	}
+}
+
+func (b *ShardedBuf[F]) position(count uint64) Position {
+	return b.origin + Position(count)
+}
+
+func (b *ShardedBuf[F]) Bounds() (Position, Position) {
+	return b.FirstPosition(), b.NextPosition()
+}
+
+func (b *ShardedBuf[F]) FirstPosition() Position {
+	return b.position(atomic.LoadUint64(&b.head))
+}
+
+func (b *ShardedBuf[F]) NextPosition() Position {
+	return b.position(atomic.LoadUint64(&b.next))
+}
+
+func (b *ShardedBuf[F]) Clone() Buffer[F] {
+	b.dropMu.Lock()
+	defer b.dropMu.Unlock()
+	b.rlockAll()
+	defer b.runlockAll()
+	shards := make([]shard[F], len(b.shards))
+	for i := range shards {
+		shards[i].ring = b.shards[i].ring.Clone().(*RingBuf[F])
+	}
+	return &ShardedBuf[F]{
+		head:   atomic.LoadUint64(&b.head),
+		next:   atomic.LoadUint64(&b.next),
+		stats:  b.stats.clone(),
+		shards: shards,
+		origin: b.origin,
+	}
+}
+
+func (b *ShardedBuf[F]) Len() int {
+	return int(atomic.LoadUint64(&b.next) - atomic.LoadUint64(&b.head))
+}
+
+func (b *ShardedBuf[F]) Cap() int {
+	return len(b.shards) * b.shards[0].ring.Cap()
+}
+
+func (b *ShardedBuf[F]) MemoryUsage() int64 {
+	var n int64
+	for k := range b.shards {
+		s := &b.shards[k]
+		s.mu.RLock()
+		n += s.ring.MemoryUsage()
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+func (b *ShardedBuf[F]) Free() int {
+	return b.Cap() - b.Len()
+}
+
+func (b *ShardedBuf[F]) IsEmpty() bool {
+	return b.Len() <= 0
+}
+
+func (b *ShardedBuf[F]) IsFull() bool {
+	return b.Free() <= 0
+}
+
+func (b *ShardedBuf[F]) Stats() Stats {
+	return b.stats.stats(b.Len())
+}
+
+func (b *ShardedBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *ShardedBuf[F]) ResetAt(start Position) {
+	b.dropMu.Lock()
+	defer b.dropMu.Unlock()
+	b.lockAll()
+	defer b.unlockAll()
+	b.stats.dropped(b.Len())
+	for i := range b.shards {
+		b.shards[i].ring.Reset()
+	}
+	b.origin = start
+	atomic.StoreUint64(&b.head, 0)
+	atomic.StoreUint64(&b.next, 0)
+}
@@ -0,0 +1,132 @@
+package ringbuf
+
+import (
+	"errors"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedBuf(t *testing.T) {
+	buf := NewShardedBuf[int](2, 2)
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.True(t, errors.Is(buf.Append(4), ErrBufferOverflow))
+
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(4))
+	assert.True(t, errors.Is(buf.Append(5), ErrBufferOverflow)) // shard 1 is full
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(5))
+
+	items, err = buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4, 5}, items)
+
+	_, err = buf.ToSlice(1)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.NoError(t, buf.Drop(0)) // no-op
+	assert.Equal(t, Position(2), buf.FirstPosition())
+	assert.True(t, errors.Is(buf.Drop(6), ErrOutOfRange))
+}
+
+func TestShardedBufStaleDrop(t *testing.T) {
+	buf := NewShardedBuf[int](2, 4)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Drop(-100)) // no-op
+	assert.Equal(t, 3, buf.Len())
+	assert.Equal(t, Position(2), buf.FirstPosition())
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, items)
+}
+
+func TestShardedBufReads(t *testing.T) {
+	checkBounds(t, NewShardedBuf[Item](3, 1))
+	checkLen(t, NewShardedBuf[Item](3, 1), 0, 3)
+	checkPositions(t, NewShardedBuf[Item](3, 1))
+	checkReset(t, NewShardedBuf[int](3, 1))
+	checkStats(t, NewShardedBuf[int](3, 1))
+	checkAppendPos(t, NewShardedBuf[int](3, 1))
+	checkCopyTo(t, NewShardedBuf[int](2, 2))
+	checkToSliceN(t, NewShardedBuf[int](2, 2))
+	checkIteratorPosition(t, NewShardedBuf[int](2, 2))
+
+	buf := NewShardedBuf[int](2, 2)
+	for i := 0; i < 6; i++ {
+		if i >= 4 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+	}
+	checkToSliceRange(t, buf)
+	checkGet(t, buf, 2, 6)
+}
+
+func TestShardedBufClone(t *testing.T) {
+	buf := NewShardedBuf[int](3, 1)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	clone := buf.Clone()
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(3))
+	assert.True(t, errors.Is(clone.Append(3), ErrBufferOverflow))
+
+	items, err := clone.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, items)
+	items, err = buf.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+}
+
+func TestShardedBufConcurrent(t *testing.T) {
+	const producers, n = 4, 2000
+	buf := NewShardedBuf[int](4, 8)
+	wg := sync.WaitGroup{}
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < n; {
+				if buf.Append(p*n+i) == nil {
+					i++
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}(p)
+	}
+
+	var received []int
+	for len(received) < producers*n {
+		start := buf.FirstPosition()
+		items, err := buf.ToSlice(start)
+		assert.NoError(t, err)
+		if len(items) == 0 {
+			runtime.Gosched()
+			continue
+		}
+		received = append(received, items...)
+		assert.NoError(t, buf.Drop(start+Position(len(items))-1))
+	}
+	wg.Wait()
+	sort.Ints(received)
+	for i, item := range received {
+		if !assert.Equal(t, i, item) {
+			break
+		}
+	}
+}
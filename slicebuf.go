@@ -0,0 +1,253 @@
+package ringbuf
+
+import (
+	"fmt"
+	"math"
+)
+
+// defaultWaste is the fraction of the backing array of a SliceBuf that may be
+// wasted before Drop compacts it.
+const defaultWaste = 0.75
+
+// NewSliceBuf returns a SliceBuf holding up to size items. It preallocates a
+// backing array of 2*size items, twice what it holds, so that moving the items
+// back to the front once half of the array is dropped costs amortized O(1) per
+// Append; MemoryUsage reports the whole array.
+func NewSliceBuf[F any](size int) *SliceBuf[F] {
+	return &SliceBuf[F]{
+		size:   size,
+		buf:    make([]F, 0, 2*size),
+		minCap: 2 * size,
+		waste:  defaultWaste,
+	}
+}
+
+// NewUnboundedSliceBuf returns a SliceBuf that grows as needed, up to the
+// 1<<31-1 items that Position can address.
+func NewUnboundedSliceBuf[F any]() *SliceBuf[F] {
+	return &SliceBuf[F]{
+		size:  math.MaxInt32,
+		waste: defaultWaste,
+	}
+}
+
+// SliceBuf is a Buffer keeping the items in a single slice, so that the read
+// methods return a subslice without copying. The slices are only valid until
+// the next mutation of the buffer.
+//
+// Dropped items leave room at the front of the backing array. Once it is
+// full and at least half of it is dropped, Append moves the items back to the
+// front instead of growing the array. Drop moves them to a smaller array when
+// the backing array has grown beyond what NewSliceBuf preallocates and most
+// of it is wasted, see SetCompaction.
+//
+// The dropped items are cut off the front of the slice, so Bounds starts at
+// the first position, and dropping below it is a no-op.
+type SliceBuf[F any] struct {
+	stats  counters
+	size   int
+	buf    []F // buf[head:] are the items
+	head   int
+	base   Position
+	minCap int     // capacity that compaction does not go below
+	waste  float64 // wasted fraction of cap(buf) triggering compaction
+}
+
+// SetCompaction makes Drop compact the backing array once more than the
+// fraction waste of it holds no items. The array is halved at most, so waste
+// must be in [0.5, 1], or SetCompaction panics; 1 disables compaction.
+func (b *SliceBuf[F]) SetCompaction(waste float64) {
+	if !(0.5 <= waste && waste <= 1) {
+		panic(fmt.Sprintf("ringbuf: compaction threshold %v not in [0.5, 1]", waste))
+	}
+	b.waste = waste
+}
+
+func (b *SliceBuf[F]) Drop(drop Position) error {
+	base := b.base
+	if b.Len() <= int(drop-base) { // base + len(items) <= drop
+		return b.stats.fail(errOutOfRange(drop, base, b.NextPosition()))
+	}
+	if 0 <= drop-base { // base <= drop
+		n := int(drop - base + 1)
+		b.stats.dropped(n)
+		b.head += n
+		b.base = drop + 1
+		b.compact()
+	}
+	return nil
+}
+
+// compact moves the items to a fresh array of twice their number if more
+// than the fraction waste of the backing array is wasted.
+func (b *SliceBuf[F]) compact() {
+	if cap(b.buf) <= b.minCap || float64(cap(b.buf)-b.Len()) <= b.waste*float64(cap(b.buf)) {
+		return
+	}
+	size := 2 * b.Len()
+	if size < b.minCap {
+		size = b.minCap
+	}
+	buf := make([]F, b.Len(), size)
+	copy(buf, b.items())
+	b.buf = buf
+	b.head = 0
+}
+
+func (b *SliceBuf[F]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *SliceBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *SliceBuf[F]) AppendPos(item F) (Position, error) {
+	pos := b.NextPosition()
+	if b.size <= b.Len() {
+		return pos, b.stats.fail(errOverflow(b.base, b.size))
+	}
+	if len(b.buf) == cap(b.buf) && len(b.buf) <= 2*b.head {
+		b.rebase()
+	}
+	b.buf = append(b.buf, item)
+	b.stats.appended(1, b.Len())
+	return pos, nil
+}
+
+// rebase moves the items to the front of the backing array.
+func (b *SliceBuf[F]) rebase() {
+	n := copy(b.buf, b.buf[b.head:])
+	var zero F
+	for i := n; i < len(b.buf); i++ {
+		b.buf[i] = zero
+	}
+	b.buf = b.buf[:n]
+	b.head = 0
+}
+
+func (b *SliceBuf[F]) items() []F {
+	return b.buf[b.head:]
+}
+
+func (b *SliceBuf[F]) Iterator(start Position) (*Iterator[F], error) {
+	ss, err := b.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	return NewIteratorAt[F](start, ss), nil
+}
+
+func (b *SliceBuf[F]) ToSlice(start Position) ([]F, error) {
+	if start-b.base < 0 || b.Len() < int(start-b.base) {
+		return nil, b.stats.fail(errOutOfRange(start, b.base, b.NextPosition()))
+	}
+	return b.items()[start-b.base:], nil
+}
+
+func (b *SliceBuf[F]) Bounds() (Position, Position) {
+	return b.base, b.NextPosition()
+}
+
+func (b *SliceBuf[F]) Clone() Buffer[F] {
+	buf := make([]F, b.Len(), cap(b.buf))
+	copy(buf, b.items())
+	return &SliceBuf[F]{
+		stats:  b.stats.clone(),
+		size:   b.size,
+		buf:    buf,
+		base:   b.base,
+		minCap: b.minCap,
+		waste:  b.waste,
+	}
+}
+
+func (b *SliceBuf[F]) Len() int {
+	return len(b.buf) - b.head
+}
+
+func (b *SliceBuf[F]) Cap() int {
+	return b.size
+}
+
+func (b *SliceBuf[F]) MemoryUsage() int64 {
+	return int64(cap(b.buf)) * sizeOf[F]()
+}
+
+func (b *SliceBuf[F]) Free() int {
+	return b.size - b.Len()
+}
+
+func (b *SliceBuf[F]) IsEmpty() bool {
+	return b.Len() <= 0
+}
+
+func (b *SliceBuf[F]) IsFull() bool {
+	return b.Free() <= 0
+}
+
+func (b *SliceBuf[F]) Get(pos Position) (F, error) {
+	if pos-b.base < 0 || b.Len() <= int(pos-b.base) {
+		var zero F
+		return zero, b.stats.fail(errOutOfRange(pos, b.base, b.NextPosition()))
+	}
+	return b.items()[pos-b.base], nil
+}
+
+func (b *SliceBuf[F]) ToSliceRange(start, end Position) ([]F, error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	if end-start < 0 || len(items) < int(end-start) {
+		return nil, b.stats.fail(errOutOfRange(end, b.base, b.NextPosition()))
+	}
+	return items[:end-start], nil
+}
+
+func (b *SliceBuf[F]) CopyTo(dst []F, start Position) (int, error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return 0, err
+	}
+	return copy(dst, items), nil
+}
+
+func (b *SliceBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *SliceBuf[F]) ResetAt(start Position) {
+	b.stats.dropped(b.Len())
+	b.head = len(b.buf)
+	b.rebase()
+	b.compact()
+	b.base = start
+}
+
+func (b *SliceBuf[F]) FirstPosition() Position {
+	return b.base
+}
+
+func (b *SliceBuf[F]) NextPosition() Position {
+	return b.base + Position(b.Len())
+}
+
+func (b *SliceBuf[F]) ToSliceN(start Position, max int) ([]F, error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	if max <= 0 {
+		return items[:0], nil
+	}
+	if max < len(items) {
+		items = items[:max]
+	}
+	return items, nil
+}
+
+func (b *SliceBuf[F]) Stats() Stats {
+	return b.stats.stats(b.Len())
+}
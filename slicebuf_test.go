@@ -0,0 +1,134 @@
+package ringbuf
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceBuf(t *testing.T) {
+	buf := NewSliceBuf[int](3)
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.Append(1))
+	assert.NoError(t, buf.Append(2))
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(3))
+	items, err = buf.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+
+	_, err = buf.ToSlice(0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.NoError(t, buf.Drop(-1)) // no-op
+	assert.Equal(t, Position(1), buf.FirstPosition())
+	assert.True(t, errors.Is(buf.Drop(4), ErrOutOfRange))
+
+	// the errors of every read report the same bounds
+	want := OutOfRangeError{Low: 1, High: 4}
+	_, err = buf.ToSlice(0)
+	want.Requested = 0
+	assert.Equal(t, want, err)
+	_, err = buf.ToSliceRange(2, 5)
+	want.Requested = 5
+	assert.Equal(t, want, err)
+	_, err = buf.Get(4)
+	want.Requested = 4
+	assert.Equal(t, want, err)
+}
+
+func TestSliceBufReads(t *testing.T) {
+	checkBounds(t, NewSliceBuf[Item](3))
+	checkLen(t, NewSliceBuf[Item](3), 0, 3)
+	checkPositions(t, NewSliceBuf[Item](3))
+	checkReset(t, NewSliceBuf[int](3))
+	checkCopyTo(t, NewSliceBuf[int](4))
+	checkToSliceN(t, NewSliceBuf[int](4))
+	checkIteratorPosition(t, NewSliceBuf[int](4))
+
+	buf := NewSliceBuf[int](4)
+	for i := 0; i < 6; i++ {
+		if i >= 4 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+	}
+	checkToSliceRange(t, buf)
+	checkGet(t, buf, 2, 6)
+}
+
+func TestSliceBufRebase(t *testing.T) {
+	buf := NewSliceBuf[int](4)
+	for i := 0; i < 100; i++ {
+		if i >= 4 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+		assert.Equal(t, 8, cap(buf.buf))
+	}
+	items, err := buf.ToSlice(96)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{96, 97, 98, 99}, items)
+	for _, item := range buf.buf[len(buf.buf):cap(buf.buf)] {
+		assert.Equal(t, 0, item)
+	}
+}
+
+func TestUnboundedSliceBuf(t *testing.T) {
+	buf := NewUnboundedSliceBuf[int]()
+	assert.Equal(t, math.MaxInt32, buf.Cap())
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, 1000, buf.Len())
+	assert.NoError(t, buf.Drop(899))
+	items, err := buf.ToSlice(900)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, len(items))
+	assert.Equal(t, 900, items[0])
+	assert.Equal(t, 999, items[99])
+}
+
+func TestSliceBufCompaction(t *testing.T) {
+	buf := NewUnboundedSliceBuf[int]()
+	for i := 0; i < 1000; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	size := cap(buf.buf)
+	assert.NoError(t, buf.Drop(499))
+	assert.Equal(t, size, cap(buf.buf))
+	assert.NoError(t, buf.Drop(899))
+	assert.Equal(t, 200, cap(buf.buf))
+	items, err := buf.ToSlice(900)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, len(items))
+	assert.Equal(t, 900, items[0])
+
+	buf.Reset()
+	assert.Equal(t, 0, cap(buf.buf))
+	assert.NoError(t, buf.Append(0))
+
+	assert.PanicsWithValue(t, "ringbuf: compaction threshold 0.25 not in [0.5, 1]", func() { buf.SetCompaction(0.25) })
+	assert.Equal(t, defaultWaste, buf.waste)
+	buf.SetCompaction(1)
+	for i := 1; i < 1000; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	size = cap(buf.buf)
+	assert.NoError(t, buf.Drop(998))
+	assert.Equal(t, size, cap(buf.buf))
+
+	bounded := NewSliceBuf[int](4)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, bounded.Append(i))
+	}
+	assert.NoError(t, bounded.Drop(3))
+	assert.Equal(t, 8, cap(bounded.buf))
+}
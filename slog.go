@@ -0,0 +1,22 @@
+//go:build go1.21
+
+package ringbuf
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogLogger returns a Logger writing to l at level.
+func NewSlogLogger(l *slog.Logger, level slog.Level) Logger {
+	return slogLogger{l: l, level: level}
+}
+
+type slogLogger struct {
+	l     *slog.Logger
+	level slog.Level
+}
+
+func (s slogLogger) Log(msg string, keyvals ...any) {
+	s.l.Log(context.Background(), s.level, msg, keyvals...)
+}
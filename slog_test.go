@@ -0,0 +1,28 @@
+//go:build go1.21
+
+package ringbuf
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var w bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&w, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	buf := NewRingBuf[int](1, WithLogger[int](NewSlogLogger(logger, slog.LevelWarn), 1, time.Minute))
+	assert.NoError(t, buf.Append(0))
+	assert.Error(t, buf.Append(1))
+	assert.Equal(t, "level=WARN msg=\"ringbuf: buffer overflow\" first=0 next=1 cap=1\n", w.String())
+}
@@ -0,0 +1,225 @@
+package ringbuf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Codec converts items to and from bytes for Snapshot and Restore.
+type Codec[F any] interface {
+	Encode(item F) ([]byte, error)
+	Decode(data []byte) (F, error)
+}
+
+// Snapshot encodes the capacity, the first position and the retained items
+// with the codec given by WithCodec.
+func (b *RingBuf[F]) Snapshot() ([]byte, error) {
+	if b.codec == nil {
+		return nil, fmt.Errorf("%w: no codec", ErrInvalidState)
+	}
+	return b.snapshot(nil, b.codec)
+}
+
+func (b *RingBuf[F]) snapshot(data []byte, codec Codec[F]) ([]byte, error) {
+	w := bytes.NewBuffer(data)
+	if err := b.encode(w, codec); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+// Encode writes the state of the buffer to w in the format of Snapshot,
+// encoding the items one by one with codec.
+func (b *RingBuf[F]) Encode(w io.Writer, codec Codec[F]) error {
+	bw := bufio.NewWriter(w)
+	if err := b.encode(bw, codec); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (b *RingBuf[F]) encode(w io.Writer, codec Codec[F]) error {
+	first := b.FirstPosition()
+	head, tail, err := b.iter(first)
+	if err != nil {
+		return err
+	}
+	var p [3 * binary.MaxVarintLen64]byte
+	header := appendUvarint(p[:0], uint64(len(b.buf)))
+	header = appendVarint(header, int64(first))
+	header = appendUvarint(header, uint64(len(head)+len(tail)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	for _, items := range [][]F{head, tail} {
+		for _, item := range items {
+			data, err := codec.Encode(item)
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(appendUvarint(p[:0], uint64(len(data)))); err != nil {
+				return err
+			}
+			if _, err := w.Write(data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Restore replaces the state of the buffer, including its capacity, with a
+// snapshot taken by Snapshot. Hooks are not called for the restored items. A
+// snapshot larger than WithMaxRestoreSize is rejected with ErrInvalidState.
+func (b *RingBuf[F]) Restore(data []byte) error {
+	if b.codec == nil {
+		return fmt.Errorf("%w: no codec", ErrInvalidState)
+	}
+	return b.restore(data, b.codec)
+}
+
+func (b *RingBuf[F]) restore(data []byte, codec Codec[F]) error {
+	return b.decode(bytes.NewReader(data), codec)
+}
+
+// Decode replaces the state of the buffer like Restore with the state read
+// from r, decoding the items one by one with codec. Unless r is an
+// io.ByteReader, Decode may read past the end of the state.
+func (b *RingBuf[F]) Decode(r io.Reader, codec Codec[F]) error {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return b.decode(br, codec)
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func (b *RingBuf[F]) decode(r byteReader, codec Codec[F]) error {
+	size, err := binary.ReadUvarint(r)
+	if err != nil {
+		return malformed(err)
+	}
+	first, err := binary.ReadVarint(r)
+	if err != nil {
+		return malformed(err)
+	}
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return malformed(err)
+	}
+	if size == 0 || size < n || math.MaxInt32 < size {
+		return fmt.Errorf("%w: malformed snapshot", ErrInvalidState)
+	}
+	if max := b.maxRestoreSize(); uint64(max) < size {
+		return fmt.Errorf("%w: snapshot of %v items exceeds %v", ErrInvalidState, size, max)
+	}
+	buf := make([]F, size)
+	for i := range buf[:n] {
+		m, err := binary.ReadUvarint(r)
+		if err != nil {
+			return malformed(err)
+		}
+		if math.MaxInt64 < m {
+			return fmt.Errorf("%w: malformed snapshot", ErrInvalidState)
+		}
+		// grown as the bytes are read rather than trusting m, and not reused
+		// as the codec may retain it
+		var data bytes.Buffer
+		if _, err := io.CopyN(&data, r, int64(m)); err != nil {
+			return malformed(err)
+		}
+		item, err := codec.Decode(data.Bytes())
+		if err != nil {
+			return err
+		}
+		buf[i] = item
+	}
+	b.load(buf, Position(first), int(n))
+	return nil
+}
+
+func (b *RingBuf[F]) maxRestoreSize() int {
+	if b.maxRestore == 0 {
+		return defaultMaxRestoreSize
+	}
+	return b.maxRestore
+}
+
+// malformed reports a truncated snapshot as ErrInvalidState, and returns the
+// other read errors as is.
+func malformed(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: malformed snapshot", ErrInvalidState)
+	}
+	return err
+}
+
+const binaryVersion = 1
+
+// MarshalBinary encodes the buffer like Snapshot after a version byte. Items
+// are encoded with the codec given by WithCodec, or with their own
+// MarshalBinary method if there is none.
+func (b *RingBuf[F]) MarshalBinary() ([]byte, error) {
+	return b.snapshot([]byte{binaryVersion}, b.binaryCodec())
+}
+
+// UnmarshalBinary restores the buffer from data encoded by MarshalBinary. If
+// no codec is given by WithCodec, *F must implement
+// encoding.BinaryUnmarshaler.
+func (b *RingBuf[F]) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != binaryVersion {
+		return fmt.Errorf("%w: unsupported binary version", ErrInvalidState)
+	}
+	return b.restore(data[1:], b.binaryCodec())
+}
+
+func (b *RingBuf[F]) binaryCodec() Codec[F] {
+	if b.codec != nil {
+		return b.codec
+	}
+	return binaryCodec[F]{}
+}
+
+// binaryCodec encodes items with encoding.BinaryMarshaler and decodes them
+// with encoding.BinaryUnmarshaler implemented by *F.
+type binaryCodec[F any] struct{}
+
+func (binaryCodec[F]) Encode(item F) ([]byte, error) {
+	m, ok := any(item).(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a BinaryMarshaler", ErrInvalidState, item)
+	}
+	return m.MarshalBinary()
+}
+
+func (binaryCodec[F]) Decode(data []byte) (F, error) {
+	var item F
+	u, ok := any(&item).(encoding.BinaryUnmarshaler)
+	if !ok {
+		return item, fmt.Errorf("%w: %T is not a BinaryUnmarshaler", ErrInvalidState, &item)
+	}
+	err := u.UnmarshalBinary(data)
+	return item, err
+}
+
+func appendUvarint(dst []byte, v uint64) []byte {
+	var p [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(p[:], v)
+	return append(dst, p[:n]...)
+}
+
+func appendVarint(dst []byte, v int64) []byte {
+	var p [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(p[:], v)
+	return append(dst, p[:n]...)
+}
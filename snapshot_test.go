@@ -0,0 +1,196 @@
+package ringbuf
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type intCodec struct{}
+
+func (intCodec) Encode(item int) ([]byte, error) {
+	return []byte(strconv.Itoa(item)), nil
+}
+
+func (intCodec) Decode(data []byte) (int, error) {
+	return strconv.Atoi(string(data))
+}
+
+func TestRingBufferSnapshot(t *testing.T) {
+	buf := NewRingBuf[int](4, WithCodec[int](intCodec{}))
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(4)) // wrap around
+	data, err := buf.Snapshot()
+	assert.NoError(t, err)
+
+	restored := NewRingBuf[int](2, WithCodec[int](intCodec{}))
+	assert.NoError(t, restored.Restore(data))
+	assert.Equal(t, 4, restored.Cap())
+	assert.Equal(t, Position(2), restored.FirstPosition())
+	assert.Equal(t, Position(5), restored.NextPosition())
+	items, err := restored.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, items)
+
+	assert.NoError(t, restored.Append(5))
+	assert.True(t, errors.Is(restored.Append(6), ErrBufferOverflow))
+
+	empty := NewRingBuf[int](3, WithCodec[int](intCodec{}))
+	empty.ResetAt(100)
+	data, err = empty.Snapshot()
+	assert.NoError(t, err)
+	assert.NoError(t, restored.Restore(data))
+	assert.Equal(t, 0, restored.Len())
+	assert.Equal(t, Position(100), restored.NextPosition())
+}
+
+func TestRingBufferSnapshotErrors(t *testing.T) {
+	_, err := NewRingBuf[int](3).Snapshot()
+	assert.True(t, errors.Is(err, ErrInvalidState))
+
+	buf := NewRingBuf[int](3, WithCodec[int](intCodec{}))
+	assert.NoError(t, buf.Append(1))
+	data, err := buf.Snapshot()
+	assert.NoError(t, err)
+	assert.True(t, errors.Is(buf.Restore(data[:len(data)-1]), ErrInvalidState))
+	assert.True(t, errors.Is(buf.Restore(nil), ErrInvalidState))
+
+	// an item length of 1<<62 with no bytes following it
+	huge := appendUvarint([]byte{4, 0, 1}, 1<<62)
+	assert.Equal(t, 12, len(huge))
+	assert.True(t, errors.Is(buf.Restore(huge), ErrInvalidState))
+	assert.True(t, errors.Is(buf.UnmarshalBinary(append([]byte{binaryVersion}, huge...)), ErrInvalidState))
+
+	// a capacity of 1<<31-1 with no items
+	large := appendUvarint(nil, 1<<31-1)
+	large = append(large, 0, 0)
+	assert.True(t, errors.Is(buf.Restore(large), ErrInvalidState))
+
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, items)
+}
+
+func TestRingBufferMaxRestoreSize(t *testing.T) {
+	src := NewRingBuf[int](8, WithCodec[int](intCodec{}))
+	assert.NoError(t, src.Append(1))
+	data, err := src.Snapshot()
+	assert.NoError(t, err)
+
+	buf := NewRingBuf[int](4, WithCodec[int](intCodec{}), WithMaxRestoreSize[int](4))
+	assert.True(t, errors.Is(buf.Restore(data), ErrInvalidState))
+	assert.Equal(t, 4, buf.Cap())
+	buf = NewRingBuf[int](4, WithCodec[int](intCodec{}), WithMaxRestoreSize[int](8))
+	assert.NoError(t, buf.Restore(data))
+	assert.Equal(t, 8, buf.Cap())
+}
+
+func TestRingBufferEncode(t *testing.T) {
+	buf := NewRingBuf[int](4)
+	buf.ResetAt(10)
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(10))
+	assert.NoError(t, buf.Append(4))
+	var w bytes.Buffer
+	assert.NoError(t, buf.Encode(&w, intCodec{}))
+	w.WriteString("trailer")
+
+	restored := NewRingBuf[int](1)
+	assert.NoError(t, restored.Decode(&w, intCodec{}))
+	assert.Equal(t, "trailer", w.String()) // a ByteReader is not read ahead
+	assert.Equal(t, 4, restored.Cap())
+	items, err := restored.ToSlice(11)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, items)
+
+	// the format is that of Snapshot
+	data, err := NewRingBuf[int](4, WithCodec[int](intCodec{})).Snapshot()
+	assert.NoError(t, err)
+	assert.NoError(t, restored.Decode(iotest.OneByteReader(bytes.NewReader(data)), intCodec{}))
+	assert.Equal(t, 0, restored.Len())
+}
+
+func TestRingBufferEncodeErrors(t *testing.T) {
+	buf := NewRingBuf[int](2)
+	assert.NoError(t, buf.Append(1))
+	assert.Equal(t, iotest.ErrTimeout, buf.Encode(&failingWriter{}, intCodec{}))
+
+	var w bytes.Buffer
+	assert.NoError(t, buf.Encode(&w, intCodec{}))
+	data := w.Bytes()
+	err := buf.Decode(bytes.NewReader(data[:len(data)-1]), intCodec{})
+	assert.True(t, errors.Is(err, ErrInvalidState))
+	err = buf.Decode(iotest.TimeoutReader(iotest.OneByteReader(bytes.NewReader(data))), intCodec{})
+	assert.Equal(t, iotest.ErrTimeout, err)
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, items)
+}
+
+type failingWriter struct{}
+
+func (*failingWriter) Write(p []byte) (int, error) {
+	return 0, iotest.ErrTimeout
+}
+
+type point struct{ x, y byte }
+
+func (p point) MarshalBinary() ([]byte, error) {
+	return []byte{p.x, p.y}, nil
+}
+
+func (p *point) UnmarshalBinary(data []byte) error {
+	if len(data) != 2 {
+		return errors.New("invalid point")
+	}
+	p.x, p.y = data[0], data[1]
+	return nil
+}
+
+func TestRingBufferMarshalBinary(t *testing.T) {
+	buf := NewRingBuf[point](3)
+	buf.ResetAt(-2)
+	for i := byte(0); i < 3; i++ {
+		assert.NoError(t, buf.Append(point{i, i + 1}))
+	}
+	assert.NoError(t, buf.Drop(-2))
+	data, err := buf.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, byte(binaryVersion), data[0])
+
+	var restored RingBuf[point]
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	assert.Equal(t, Position(-1), restored.FirstPosition())
+	items, err := restored.ToSlice(-1)
+	assert.NoError(t, err)
+	assert.Equal(t, []point{{1, 2}, {2, 3}}, items)
+
+	data[0] = 0
+	assert.True(t, errors.Is(restored.UnmarshalBinary(data), ErrInvalidState))
+}
+
+func TestRingBufferMarshalBinaryCodec(t *testing.T) {
+	buf := NewRingBuf[int](2)
+	assert.NoError(t, buf.Append(1))
+	_, err := buf.MarshalBinary()
+	assert.True(t, errors.Is(err, ErrInvalidState))
+
+	buf = NewRingBuf[int](2, WithCodec[int](intCodec{}))
+	assert.NoError(t, buf.Append(1))
+	data, err := buf.MarshalBinary()
+	assert.NoError(t, err)
+	restored := NewRingBuf[int](1, WithCodec[int](intCodec{}))
+	assert.NoError(t, restored.UnmarshalBinary(data))
+	items, err := restored.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, items)
+}
@@ -0,0 +1,289 @@
+package ringbuf
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// NewSnapshotBuf returns an empty SnapshotBuf holding up to size items.
+func NewSnapshotBuf[F any](size int) *SnapshotBuf[F] {
+	b := &SnapshotBuf[F]{
+		size: size,
+	}
+	b.publish()
+	return b
+}
+
+// SnapshotBuf is a Buffer for read-mostly workloads. Writers serialize on a
+// mutex and publish an immutable view of the items after each Append,
+// AppendAll, Drop or Reset; the read methods load the latest view
+// atomically and never take a lock.
+//
+// Writers only append past the end of every published view and move to a
+// fresh array of twice the capacity once the current one is full, so
+// publishing costs amortized O(1) per item. The slices returned by the read
+// methods alias a view and must not be modified.
+//
+// Drop publishes a view starting at the new first position. Readers holding
+// an older view keep seeing the dropped items, but Bounds and later reads start
+// at the first position. Dropping below it is a no-op.
+type SnapshotBuf[F any] struct {
+	stats counters
+	mu    sync.Mutex
+	size  int
+	buf   []F // buf[head:] are the items
+	head  int
+	first Position
+	view  atomic.Value // *snapshotView[F]
+}
+
+// snapshotView is an immutable state of a SnapshotBuf.
+type snapshotView[F any] struct {
+	items []F
+	first Position
+}
+
+func (v *snapshotView[F]) next() Position {
+	return v.first + Position(len(v.items))
+}
+
+func (b *SnapshotBuf[F]) load() *snapshotView[F] {
+	return b.view.Load().(*snapshotView[F])
+}
+
+// publish stores the current items as the latest view. The caller must
+// hold mu.
+func (b *SnapshotBuf[F]) publish() {
+	items := b.buf[b.head:len(b.buf):len(b.buf)]
+	b.view.Store(&snapshotView[F]{items: items, first: b.first})
+}
+
+// grow makes room for n more items past the end of the published view. The
+// caller must hold mu.
+func (b *SnapshotBuf[F]) grow(n int) {
+	if len(b.buf)+n <= cap(b.buf) {
+		return
+	}
+	buf := make([]F, len(b.buf)-b.head, 2*b.size)
+	copy(buf, b.buf[b.head:])
+	b.buf = buf
+	b.head = 0
+}
+
+func (b *SnapshotBuf[F]) Drop(drop Position) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := int(drop - b.first + 1)
+	if len(b.buf)-b.head < n {
+		return b.stats.fail(errOutOfRange(drop, b.first, b.first+Position(len(b.buf)-b.head)))
+	}
+	if 0 < n {
+		b.head += n
+		b.first += Position(n)
+		b.stats.dropped(n)
+		b.publish()
+	}
+	return nil
+}
+
+// Roll drops up to drop and appends items, publishing them as one view.
+func (b *SnapshotBuf[F]) Roll(drop Position, items ...F) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	size := len(b.buf) - b.head
+	n := int(drop - b.first + 1)
+	if size < n {
+		return b.stats.fail(errOutOfRange(drop, b.first, b.first+Position(size)))
+	}
+	if n < 0 {
+		n = 0
+	}
+	if b.size < size-n+len(items) {
+		return b.stats.fail(errOverflow(b.first, b.size))
+	}
+	if 0 < n {
+		b.head += n
+		b.first += Position(n)
+		b.stats.dropped(n)
+	}
+	if 0 < len(items) {
+		b.grow(len(items))
+		b.buf = append(b.buf, items...)
+		b.stats.appended(len(items), len(b.buf)-b.head)
+	}
+	b.publish()
+	return nil
+}
+
+func (b *SnapshotBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *SnapshotBuf[F]) AppendPos(item F) (Position, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	pos := b.first + Position(len(b.buf)-b.head)
+	if b.size <= len(b.buf)-b.head {
+		return pos, b.stats.fail(errOverflow(b.first, b.size))
+	}
+	b.grow(1)
+	b.buf = append(b.buf, item)
+	b.stats.appended(1, len(b.buf)-b.head)
+	b.publish()
+	return pos, nil
+}
+
+// AppendAll appends items and publishes them as one view. It returns the
+// position of the first item, or ErrBufferOverflow without appending
+// anything if they do not fit.
+func (b *SnapshotBuf[F]) AppendAll(items []F) (Position, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	next := b.first + Position(len(b.buf)-b.head)
+	if b.size < len(b.buf)-b.head+len(items) {
+		return next, b.stats.fail(errOverflow(b.first, b.size))
+	}
+	if len(items) == 0 {
+		return next, nil
+	}
+	b.grow(len(items))
+	b.buf = append(b.buf, items...)
+	b.stats.appended(len(items), len(b.buf)-b.head)
+	b.publish()
+	return next, nil
+}
+
+func (b *SnapshotBuf[F]) Iterator(start Position) (*Iterator[F], error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	return NewIteratorAt[F](start, items), nil
+}
+
+func (b *SnapshotBuf[F]) ToSlice(start Position) ([]F, error) {
+	v := b.load()
+	n := int(start - v.first)
+	if n < 0 || len(v.items) < n {
+		return nil, b.stats.fail(errOutOfRange(start, v.first, v.next()))
+	}
+	return v.items[n:], nil
+}
+
+func (b *SnapshotBuf[F]) ToSliceRange(start, end Position) ([]F, error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	if end-start < 0 || len(items) < int(end-start) {
+		return nil, b.stats.fail(errOutOfRange(end, start, start+Position(len(items))))
+	}
+	return items[:end-start], nil
+}
+
+func (b *SnapshotBuf[F]) ToSliceN(start Position, max int) ([]F, error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	if max <= 0 {
+		return items[:0], nil
+	}
+	if max < len(items) {
+		items = items[:max]
+	}
+	return items, nil
+}
+
+func (b *SnapshotBuf[F]) CopyTo(dst []F, start Position) (int, error) {
+	items, err := b.ToSlice(start)
+	if err != nil {
+		return 0, err
+	}
+	return copy(dst, items), nil
+}
+
+func (b *SnapshotBuf[F]) Get(pos Position) (F, error) {
+	v := b.load()
+	n := int(pos - v.first)
+	if n < 0 || len(v.items) <= n {
+		var zero F
+		return zero, b.stats.fail(errOutOfRange(pos, v.first, v.next()))
+	}
+	return v.items[n], nil
+}
+
+func (b *SnapshotBuf[F]) Bounds() (Position, Position) {
+	v := b.load()
+	return v.first, v.next()
+}
+
+func (b *SnapshotBuf[F]) FirstPosition() Position {
+	return b.load().first
+}
+
+func (b *SnapshotBuf[F]) NextPosition() Position {
+	return b.load().next()
+}
+
+func (b *SnapshotBuf[F]) Clone() Buffer[F] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	buf := make([]F, len(b.buf)-b.head, b.size)
+	copy(buf, b.buf[b.head:])
+	clone := &SnapshotBuf[F]{
+		stats: b.stats.clone(),
+		size:  b.size,
+		buf:   buf,
+		first: b.first,
+	}
+	clone.publish()
+	return clone
+}
+
+func (b *SnapshotBuf[F]) Len() int {
+	return len(b.load().items)
+}
+
+func (b *SnapshotBuf[F]) Cap() int {
+	return b.size
+}
+
+// MemoryUsage counts the current array only, not the older ones that
+// readers may still hold.
+func (b *SnapshotBuf[F]) MemoryUsage() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return int64(cap(b.buf)) * sizeOf[F]()
+}
+
+func (b *SnapshotBuf[F]) Free() int {
+	return b.size - b.Len()
+}
+
+func (b *SnapshotBuf[F]) IsEmpty() bool {
+	return b.Len() <= 0
+}
+
+func (b *SnapshotBuf[F]) IsFull() bool {
+	return b.Free() <= 0
+}
+
+func (b *SnapshotBuf[F]) Stats() Stats {
+	return b.stats.stats(b.Len())
+}
+
+func (b *SnapshotBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *SnapshotBuf[F]) ResetAt(start Position) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats.dropped(len(b.buf) - b.head)
+	b.buf = nil
+	b.head = 0
+	b.first = start
+	b.publish()
+}
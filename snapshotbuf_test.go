@@ -0,0 +1,138 @@
+package ringbuf
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotBuf(t *testing.T) {
+	buf := NewSnapshotBuf[int](3)
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.Append(1))
+	assert.NoError(t, buf.Append(2))
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(3))
+	items, err = buf.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+
+	_, err = buf.ToSlice(0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.NoError(t, buf.Drop(-1)) // no-op
+	assert.Equal(t, Position(1), buf.FirstPosition())
+	assert.True(t, errors.Is(buf.Drop(4), ErrOutOfRange))
+
+	pos, err := buf.AppendAll([]int{4, 5})
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, Position(4), pos)
+	assert.NoError(t, buf.Drop(2))
+	pos, err = buf.AppendAll([]int{4, 5})
+	assert.NoError(t, err)
+	assert.Equal(t, Position(4), pos)
+	items, err = buf.ToSlice(3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 4, 5}, items)
+}
+
+func TestSnapshotBufReads(t *testing.T) {
+	checkBounds(t, NewSnapshotBuf[Item](3))
+	checkLen(t, NewSnapshotBuf[Item](3), 0, 3)
+	checkPositions(t, NewSnapshotBuf[Item](3))
+	checkReset(t, NewSnapshotBuf[int](3))
+	checkStats(t, NewSnapshotBuf[int](3))
+	checkAppendPos(t, NewSnapshotBuf[int](3))
+	checkCopyTo(t, NewSnapshotBuf[int](4))
+	checkToSliceN(t, NewSnapshotBuf[int](4))
+	checkIteratorPosition(t, NewSnapshotBuf[int](4))
+
+	buf := NewSnapshotBuf[int](4)
+	for i := 0; i < 6; i++ {
+		if i >= 4 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+	}
+	checkToSliceRange(t, buf)
+	checkGet(t, buf, 2, 6)
+}
+
+func TestSnapshotBufImmutableView(t *testing.T) {
+	buf := NewSnapshotBuf[int](2)
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.Append(1))
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+
+	for i := 2; i < 8; i++ {
+		assert.NoError(t, buf.Drop(Position(i-2)))
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, []int{0, 1}, items)
+	items, err = buf.ToSlice(6)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{6, 7}, items)
+}
+
+func TestSnapshotBufClone(t *testing.T) {
+	buf := NewSnapshotBuf[int](3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	clone := buf.Clone()
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3))
+	assert.True(t, errors.Is(clone.Append(3), ErrBufferOverflow))
+
+	items, err := clone.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, items)
+	items, err = buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, items)
+}
+
+func TestSnapshotBufConcurrent(t *testing.T) {
+	const n = 10000
+	buf := NewSnapshotBuf[int](64)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; {
+			if buf.Append(i) == nil {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	var received []int
+	for len(received) < n {
+		start := buf.FirstPosition()
+		items, err := buf.ToSlice(start)
+		assert.NoError(t, err)
+		if len(items) == 0 {
+			runtime.Gosched()
+			continue
+		}
+		received = append(received, items...)
+		assert.NoError(t, buf.Drop(start+Position(len(items))-1))
+	}
+	wg.Wait()
+	for i, item := range received {
+		if !assert.Equal(t, i, item) {
+			break
+		}
+	}
+}
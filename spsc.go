@@ -0,0 +1,231 @@
+package ringbuf
+
+import (
+	"sync/atomic"
+)
+
+const cacheLine = 64
+
+type paddedCounter struct {
+	v uint64
+	_ [cacheLine - 8]byte
+}
+
+func (c *paddedCounter) load() uint64 {
+	return atomic.LoadUint64(&c.v)
+}
+
+func (c *paddedCounter) store(v uint64) {
+	atomic.StoreUint64(&c.v, v)
+}
+
+func (c *paddedCounter) cas(old, new uint64) bool {
+	return atomic.CompareAndSwapUint64(&c.v, old, new)
+}
+
+func NewSPSCBuf[F any](size int) *SPSCBuf[F] {
+	return &SPSCBuf[F]{
+		buf: make([]F, size),
+	}
+}
+
+// SPSCBuf is a lock-free Buffer for exactly one goroutine calling Append and
+// one goroutine calling Drop and the read methods. Clone, Reset and ResetAt
+// must not run concurrently with any other method.
+//
+// Unlike RingBuf, only the items after the last Drop can be read, and
+// dropping below the last dropped position is a no-op.
+type SPSCBuf[F any] struct {
+	head   paddedCounter // number of dropped items, written by the consumer
+	tail   paddedCounter // number of appended items, written by the producer
+	stats  counters
+	buf    []F
+	origin Position
+}
+
+func (b *SPSCBuf[F]) Drop(drop Position) error {
+	head, tail := b.head.load(), b.tail.load()
+	n := int(drop - b.position(tail)) // drop - next
+	if 0 <= n {
+		return b.stats.fail(errOutOfRange(drop, b.position(head), b.position(tail)))
+	}
+	if target := tail + uint64(n+1); head < target && target <= tail {
+		b.head.store(target)
+		b.stats.dropped(int(target - head))
+	}
+	return nil
+}
+
+func (b *SPSCBuf[F]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *SPSCBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *SPSCBuf[F]) AppendPos(item F) (Position, error) {
+	head, tail := b.head.load(), b.tail.load()
+	if uint64(len(b.buf)) <= tail-head {
+		return b.position(tail), b.stats.fail(errOverflow(b.position(head), len(b.buf)))
+	}
+	b.buf[tail%uint64(len(b.buf))] = item
+	b.tail.store(tail + 1)
+	b.stats.appended(1, int(tail+1-head))
+	return b.position(tail), nil
+}
+
+func (b *SPSCBuf[F]) Iterator(start Position) (*Iterator[F], error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return nil, err
+	}
+	return NewIteratorAt[F](start, b.copy(head, tail)), nil
+}
+
+func (b *SPSCBuf[F]) ToSlice(start Position) ([]F, error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return nil, err
+	}
+	return b.copy(head, tail), nil
+}
+
+func (b *SPSCBuf[F]) ToSliceRange(start, end Position) ([]F, error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return nil, err
+	}
+	n := int(end - start)
+	if n < 0 || len(head)+len(tail) < n {
+		low, high := b.Bounds()
+		return nil, b.stats.fail(errOutOfRange(end, low, high))
+	}
+	return b.copy(limit(head, tail, n)), nil
+}
+
+func (b *SPSCBuf[F]) ToSliceN(start Position, max int) ([]F, error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return nil, err
+	}
+	return b.copy(limit(head, tail, max)), nil
+}
+
+func (b *SPSCBuf[F]) CopyTo(dst []F, start Position) (int, error) {
+	head, tail, err := b.iter(start)
+	if err != nil {
+		return 0, err
+	}
+	n := copy(dst, head)
+	n += copy(dst[n:], tail)
+	return n, nil
+}
+
+func (b *SPSCBuf[F]) Get(pos Position) (F, error) {
+	head, tail := b.head.load(), b.tail.load()
+	n := int(pos - b.position(head))
+	if n < 0 || int(tail-head) <= n {
+		var zero F
+		return zero, b.stats.fail(errOutOfRange(pos, b.position(head), b.position(tail)))
+	}
+	return b.buf[(head+uint64(n))%uint64(len(b.buf))], nil
+}
+
+func (b *SPSCBuf[F]) iter(start Position) ([]F, []F, error) {
+	head, tail := b.head.load(), b.tail.load()
+	n := int(start - b.position(head))
+	if n < 0 || int(tail-head) < n {
+		return nil, nil, b.stats.fail(errOutOfRange(start, b.position(head), b.position(tail)))
+	}
+	size := uint64(len(b.buf))
+	begin := head + uint64(n)
+	if begin == tail {
+		return nil, nil, nil
+	}
+	i, j := begin%size, tail%size
+	if i < j {
+		return b.buf[i:j], nil, nil
+	}
+	return b.buf[i:], b.buf[:j], nil
+}
+
+func (b *SPSCBuf[F]) copy(head, tail []F) []F {
+	ret := make([]F, len(head)+len(tail))
+	n := copy(ret, head)
+	copy(ret[n:], tail)
+	return ret
+}
+
+func (b *SPSCBuf[F]) position(count uint64) Position {
+	return b.origin + Position(count)
+}
+
+func (b *SPSCBuf[F]) Bounds() (Position, Position) {
+	return b.FirstPosition(), b.NextPosition()
+}
+
+func (b *SPSCBuf[F]) FirstPosition() Position {
+	return b.position(b.head.load())
+}
+
+func (b *SPSCBuf[F]) NextPosition() Position {
+	return b.position(b.tail.load())
+}
+
+func (b *SPSCBuf[F]) Clone() Buffer[F] {
+	buf := make([]F, len(b.buf))
+	copy(buf, b.buf)
+	clone := &SPSCBuf[F]{
+		stats:  b.stats.clone(),
+		buf:    buf,
+		origin: b.origin,
+	}
+	clone.head.store(b.head.load())
+	clone.tail.store(b.tail.load())
+	return clone
+}
+
+func (b *SPSCBuf[F]) Len() int {
+	return int(b.tail.load() - b.head.load())
+}
+
+func (b *SPSCBuf[F]) Cap() int {
+	return len(b.buf)
+}
+
+func (b *SPSCBuf[F]) MemoryUsage() int64 {
+	return int64(len(b.buf)) * sizeOf[F]()
+}
+
+func (b *SPSCBuf[F]) Free() int {
+	return b.Cap() - b.Len()
+}
+
+func (b *SPSCBuf[F]) IsEmpty() bool {
+	return b.Len() <= 0
+}
+
+func (b *SPSCBuf[F]) IsFull() bool {
+	return b.Free() <= 0
+}
+
+func (b *SPSCBuf[F]) Stats() Stats {
+	return b.stats.stats(b.Len())
+}
+
+func (b *SPSCBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *SPSCBuf[F]) ResetAt(start Position) {
+	b.stats.dropped(b.Len())
+	var zero F
+	for i := range b.buf {
+		b.buf[i] = zero
+	}
+	b.origin = start
+	b.head.store(0)
+	b.tail.store(0)
+}
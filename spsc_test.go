@@ -0,0 +1,140 @@
+package ringbuf
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSPSCBuf(t *testing.T) {
+	buf := NewSPSCBuf[int](3)
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(items))
+
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.Append(1))
+	assert.NoError(t, buf.Append(2))
+	assert.True(t, errors.Is(buf.Append(3), ErrBufferOverflow))
+
+	assert.NoError(t, buf.Drop(0))
+	assert.NoError(t, buf.Append(3))
+	items, err = buf.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+
+	_, err = buf.ToSlice(0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	assert.NoError(t, buf.Drop(-1)) // no-op
+	assert.Equal(t, Position(1), buf.FirstPosition())
+	assert.True(t, errors.Is(buf.Drop(4), ErrOutOfRange))
+}
+
+func TestSPSCBufStaleDrop(t *testing.T) {
+	buf := NewSPSCBuf[int](8)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Drop(-100)) // no-op
+	assert.Equal(t, 3, buf.Len())
+	assert.Equal(t, 5, buf.Free())
+	assert.Equal(t, Position(2), buf.FirstPosition())
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, items)
+}
+
+func TestSPSCBufReads(t *testing.T) {
+	checkBounds(t, NewSPSCBuf[Item](3))
+	checkLen(t, NewSPSCBuf[Item](3), 0, 3)
+	checkPositions(t, NewSPSCBuf[Item](3))
+	checkReset(t, NewSPSCBuf[int](3))
+	checkStats(t, NewSPSCBuf[int](3))
+	checkAppendPos(t, NewSPSCBuf[int](3))
+	checkCopyTo(t, NewSPSCBuf[int](4))
+	checkToSliceN(t, NewSPSCBuf[int](4))
+	checkIteratorPosition(t, NewSPSCBuf[int](4))
+
+	buf := NewSPSCBuf[int](4)
+	for i := 0; i < 6; i++ {
+		if i >= 4 {
+			assert.NoError(t, buf.Drop(Position(i-4)))
+		}
+		assert.NoError(t, buf.Append(i))
+	}
+	checkToSliceRange(t, buf)
+	checkGet(t, buf, 2, 6)
+}
+
+func TestSPSCBufWrapAround(t *testing.T) {
+	var large int32 = (1 << 31) - 1
+	buf := NewSPSCBuf[Item](3)
+	buf.ResetAt(large - 1)
+	for pos := large - 1; pos != large+5; pos++ {
+		if buf.Free() == 0 {
+			assert.NoError(t, buf.Drop(pos-3))
+		}
+		assert.NoError(t, buf.Append(pos))
+		items, err := buf.ToSlice(buf.FirstPosition())
+		assert.NoError(t, err)
+		assert.Equal(t, pos, items[len(items)-1])
+	}
+	checkBounds(t, buf.Clone())
+}
+
+func TestSPSCBufClone(t *testing.T) {
+	buf := NewSPSCBuf[int](3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	clone := buf.Clone()
+	assert.NoError(t, buf.Drop(1))
+	assert.NoError(t, buf.Append(3))
+
+	items, err := clone.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, items)
+	items, err = buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, items)
+}
+
+func TestSPSCBufConcurrent(t *testing.T) {
+	const n = 10000
+	buf := NewSPSCBuf[int](64)
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < n; {
+			if buf.Append(i) == nil {
+				i++
+			} else {
+				runtime.Gosched()
+			}
+		}
+	}()
+
+	var received []int
+	for len(received) < n {
+		start := buf.FirstPosition()
+		items, err := buf.ToSlice(start)
+		assert.NoError(t, err)
+		if len(items) == 0 {
+			runtime.Gosched()
+			continue
+		}
+		received = append(received, items...)
+		assert.NoError(t, buf.Drop(start+Position(len(items))-1))
+	}
+	wg.Wait()
+	for i, item := range received {
+		if !assert.Equal(t, i, item) {
+			break
+		}
+	}
+}
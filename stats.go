@@ -0,0 +1,74 @@
+package ringbuf
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Stats are the counters of a Buffer since it was created.
+type Stats struct {
+	Appends     uint64 // appended items
+	Drops       uint64 // items released by Drop, Reset, ResetAt or overwriting
+	Overflows   uint64 // appends failed with ErrBufferOverflow
+	OutOfRanges uint64 // calls failed with ErrOutOfRange
+	Len         int    // current number of items
+	PeakLen     int    // highest number of items
+	MaxLag      int    // highest lag of the consumers, see Cursors.Stats
+}
+
+// counters maintains Stats. They are updated atomically so that read
+// methods can count errors under a read lock.
+type counters struct {
+	appends     uint64
+	drops       uint64
+	overflows   uint64
+	outOfRanges uint64
+	peakLen     uint64
+}
+
+func (c *counters) appended(n int, length int) {
+	atomic.AddUint64(&c.appends, uint64(n))
+	for {
+		peak := atomic.LoadUint64(&c.peakLen)
+		if uint64(length) <= peak || atomic.CompareAndSwapUint64(&c.peakLen, peak, uint64(length)) {
+			return
+		}
+	}
+}
+
+func (c *counters) dropped(n int) {
+	if 0 < n {
+		atomic.AddUint64(&c.drops, uint64(n))
+	}
+}
+
+// fail counts err and returns it.
+func (c *counters) fail(err error) error {
+	if errors.Is(err, ErrOutOfRange) {
+		atomic.AddUint64(&c.outOfRanges, 1)
+	} else if errors.Is(err, ErrBufferOverflow) {
+		atomic.AddUint64(&c.overflows, 1)
+	}
+	return err
+}
+
+func (c *counters) stats(length int) Stats {
+	return Stats{
+		Appends:     atomic.LoadUint64(&c.appends),
+		Drops:       atomic.LoadUint64(&c.drops),
+		Overflows:   atomic.LoadUint64(&c.overflows),
+		OutOfRanges: atomic.LoadUint64(&c.outOfRanges),
+		Len:         length,
+		PeakLen:     int(atomic.LoadUint64(&c.peakLen)),
+	}
+}
+
+func (c *counters) clone() counters {
+	return counters{
+		appends:     atomic.LoadUint64(&c.appends),
+		drops:       atomic.LoadUint64(&c.drops),
+		overflows:   atomic.LoadUint64(&c.overflows),
+		outOfRanges: atomic.LoadUint64(&c.outOfRanges),
+		peakLen:     atomic.LoadUint64(&c.peakLen),
+	}
+}
@@ -0,0 +1,215 @@
+package ringbuf
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NewTimedBuf returns an empty TimedBuf holding size items.
+func NewTimedBuf[F any](size int, opts ...Option[F]) *TimedBuf[F] {
+	items := NewRingBuf[F](size, opts...)
+	stamps := NewRingBuf[time.Time](size)
+	stamps.overwrite = items.overwrite
+	ring := &timedRing[F]{
+		RingBuf: items,
+		stamps:  stamps,
+		now:     time.Now,
+	}
+	return &TimedBuf[F]{
+		SyncBuf: NewSyncBuf[F](ring),
+		ring:    ring,
+	}
+}
+
+// TimedBuf is a SyncBuf that records when each item was appended, so that
+// items can also be dropped by age.
+type TimedBuf[F any] struct {
+	*SyncBuf[F]
+	ring *timedRing[F]
+}
+
+// DropOlderThan drops the items appended before t and returns how many were
+// dropped.
+func (b *TimedBuf[F]) DropOlderThan(t time.Time) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	defer b.notify()
+	first, next := b.ring.FirstPosition(), b.ring.NextPosition()
+	pos := first
+	for ; pos-next < 0; pos++ { // pos < next
+		stamp, err := b.ring.stamps.Get(pos)
+		if err != nil || !stamp.Before(t) {
+			break
+		}
+	}
+	if pos == first {
+		return 0
+	}
+	_ = b.ring.Drop(pos - 1)
+	return int(pos - first)
+}
+
+// StartEvictor starts a goroutine dropping the items older than maxAge every
+// interval until ctx is done or stop is called. stop waits for the goroutine
+// to exit.
+func (b *TimedBuf[F]) StartEvictor(ctx context.Context, interval, maxAge time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.DropOlderThan(b.ring.now().Add(-maxAge))
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+// SetRetention sets the policy applied after each Append and by Retain.
+func (b *TimedBuf[F]) SetRetention(policy RetentionPolicy[F]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ring.policy = policy
+}
+
+// Retain applies the retention policy and returns how many items were
+// dropped.
+func (b *TimedBuf[F]) Retain() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	defer b.notify()
+	return b.ring.retain()
+}
+
+// Time returns when the item at pos was appended.
+func (b *TimedBuf[F]) Time(pos Position) (time.Time, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.ring.Time(pos)
+}
+
+func (b *TimedBuf[F]) Clone() Buffer[F] {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	ring := b.ring.clone()
+	return &TimedBuf[F]{
+		SyncBuf: b.wrapClone(ring),
+		ring:    ring,
+	}
+}
+
+// CloneFunc is like SyncBuf.CloneFunc but the clone keeps the append times.
+func (b *TimedBuf[F]) CloneFunc(copyItem func(item F) F) Buffer[F] {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	ring := b.ring.cloneFunc(copyItem)
+	return &TimedBuf[F]{
+		SyncBuf: b.wrapClone(ring),
+		ring:    ring,
+	}
+}
+
+// timedRing is a RingBuf with the append time of each item at the same
+// position of stamps.
+type timedRing[F any] struct {
+	*RingBuf[F]
+	stamps *RingBuf[time.Time]
+	now    func() time.Time
+	policy RetentionPolicy[F]
+}
+
+func (b *timedRing[F]) Roll(drop Position, items ...F) error {
+	return roll[F](b, drop, items)
+}
+
+func (b *timedRing[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *timedRing[F]) AppendPos(item F) (Position, error) {
+	pos, err := b.RingBuf.AppendPos(item)
+	if err != nil {
+		return pos, err
+	}
+	if err := b.stamps.Append(b.now()); err != nil {
+		return pos, err
+	}
+	b.retain()
+	return pos, nil
+}
+
+// retain drops the items before the position kept by the policy.
+func (b *timedRing[F]) retain() int {
+	if b.policy == nil {
+		return 0
+	}
+	first, next := b.FirstPosition(), b.NextPosition()
+	keep := b.policy.Keep(b, b.now())
+	if 0 < keep-next {
+		keep = next
+	}
+	if keep-first <= 0 {
+		return 0
+	}
+	_ = b.Drop(keep - 1)
+	return int(keep - first)
+}
+
+func (b *timedRing[F]) Time(pos Position) (time.Time, error) {
+	return b.stamps.Get(pos)
+}
+
+func (b *timedRing[F]) Drop(drop Position) error {
+	if err := b.RingBuf.Drop(drop); err != nil {
+		return err
+	}
+	return b.stamps.Drop(drop)
+}
+
+func (b *timedRing[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *timedRing[F]) ResetAt(start Position) {
+	b.RingBuf.ResetAt(start)
+	b.stamps.ResetAt(start)
+}
+
+func (b *timedRing[F]) MemoryUsage() int64 {
+	return b.RingBuf.MemoryUsage() + b.stamps.MemoryUsage()
+}
+
+func (b *timedRing[F]) Clone() Buffer[F] {
+	return b.clone()
+}
+
+func (b *timedRing[F]) CloneFunc(copyItem func(item F) F) Buffer[F] {
+	return b.cloneFunc(copyItem)
+}
+
+func (b *timedRing[F]) cloneFunc(copyItem func(item F) F) *timedRing[F] {
+	clone := b.clone()
+	clone.copyItems(copyItem)
+	return clone
+}
+
+func (b *timedRing[F]) clone() *timedRing[F] {
+	return &timedRing[F]{
+		RingBuf: b.RingBuf.Clone().(*RingBuf[F]),
+		stamps:  b.stamps.Clone().(*RingBuf[time.Time]),
+		now:     b.now,
+		policy:  b.policy,
+	}
+}
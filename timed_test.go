@@ -0,0 +1,98 @@
+package ringbuf
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimedBuf(t *testing.T) {
+	buf := NewTimedBuf[int](4)
+	now := time.Unix(100, 0)
+	buf.ring.now = func() time.Time { return now }
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, buf.Append(i))
+		now = now.Add(time.Second)
+	}
+	stamp, err := buf.Time(1)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(101, 0), stamp)
+
+	assert.Equal(t, 0, buf.DropOlderThan(time.Unix(100, 0)))
+	assert.Equal(t, 2, buf.DropOlderThan(time.Unix(102, 0)))
+	assert.Equal(t, Position(2), buf.FirstPosition())
+
+	clone := buf.Clone().(*TimedBuf[int])
+	assert.NoError(t, buf.Drop(2))
+	assert.Equal(t, 1, buf.DropOlderThan(now))
+	assert.Equal(t, 0, buf.Len())
+	assert.Equal(t, 2, clone.DropOlderThan(now))
+
+	assert.NoError(t, buf.Append(4))
+	stamp, err = buf.Time(4)
+	assert.NoError(t, err)
+	assert.Equal(t, now, stamp)
+	buf.ResetAt(10)
+	assert.NoError(t, buf.Append(10))
+	assert.Equal(t, 1, buf.DropOlderThan(now.Add(time.Nanosecond)))
+}
+
+func TestTimedBufCloneFunc(t *testing.T) {
+	buf := NewTimedBuf[int](4)
+	now := time.Unix(100, 0)
+	buf.ring.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, buf.Append(i))
+		now = now.Add(time.Second)
+	}
+	assert.NoError(t, buf.Drop(0))
+
+	clone := buf.CloneFunc(func(item int) int { return item * 10 }).(*TimedBuf[int])
+	items, err := clone.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10, 20}, items)
+	stamp, err := clone.Time(2)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(102, 0), stamp)
+	assert.Equal(t, 1, clone.DropOlderThan(time.Unix(102, 0)))
+	assert.Equal(t, 2, buf.Len())
+}
+
+func TestTimedBufOverwrite(t *testing.T) {
+	buf := NewTimedBuf[int](2, WithOverwrite[int]())
+	now := time.Unix(0, 0)
+	buf.ring.now = func() time.Time { return now }
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Second)
+		assert.NoError(t, buf.Append(i))
+	}
+	assert.Equal(t, Position(1), buf.FirstPosition())
+	assert.Equal(t, 1, buf.DropOlderThan(time.Unix(3, 0)))
+	items, err := buf.ToSlice(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, items)
+}
+
+func TestTimedBufEvictor(t *testing.T) {
+	buf := NewTimedBuf[int](4)
+	var now int64 // in seconds, also read by the evictor
+	buf.ring.now = func() time.Time { return time.Unix(atomic.LoadInt64(&now), 0) }
+	assert.NoError(t, buf.Append(0))
+	evicted := buf.NotifyEvicted(0)
+	stop := buf.StartEvictor(context.Background(), time.Millisecond, time.Minute)
+	atomic.StoreInt64(&now, 61)
+	<-evicted
+	assert.Equal(t, 0, buf.Len())
+	stop()
+	stop() // stopping twice is fine
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop = buf.StartEvictor(ctx, time.Millisecond, time.Hour)
+	assert.NoError(t, buf.Append(1))
+	cancel()
+	stop()
+	assert.Equal(t, 1, buf.Len())
+}
@@ -0,0 +1,171 @@
+package ringbuf
+
+import "context"
+
+// Tracer starts a span for each operation of a Traced buffer, as a child of
+// the span in ctx, if any, given by Traced.WithContext. It is meant to be
+// adapted to a tracing library, e.g. with an OpenTelemetry trace.Tracer:
+//
+//	func (t otelTracer) Start(ctx context.Context, op string) ringbuf.Span {
+//		_, span := t.tracer.Start(ctx, "ringbuf."+op)
+//		return otelSpan{span}
+//	}
+//
+//	func (s otelSpan) End(ev ringbuf.TraceEvent) {
+//		s.span.SetAttributes(attribute.Int("ringbuf.position", int(ev.Position)),
+//			attribute.Int("ringbuf.count", ev.Count))
+//		if ev.Err != nil {
+//			s.span.RecordError(ev.Err)
+//		}
+//		s.span.End()
+//	}
+type Tracer interface {
+	Start(ctx context.Context, op string) Span
+}
+
+// Span is an operation started by a Tracer.
+type Span interface {
+	End(ev TraceEvent)
+}
+
+// TraceEvent describes an operation of a Traced buffer when it ends.
+type TraceEvent struct {
+	Position Position // position appended, dropped up to, reset to or read from
+	Count    int      // number of items appended, dropped or read
+	Err      error
+}
+
+// NewTraced returns a Traced decorating buf.
+func NewTraced[F any](buf Buffer[F], tracer Tracer) *Traced[F] {
+	return &Traced[F]{
+		Buffer: buf,
+		tracer: tracer,
+		ctx:    context.Background(),
+	}
+}
+
+// Traced is a Buffer decorator tracing Append, Drop, Reset and the reads, so
+// that a stalled buffer shows up in the traces of the caller. The operations
+// are named after the methods, e.g. "Append" or "ToSlice".
+type Traced[F any] struct {
+	Buffer[F]
+	tracer Tracer
+	ctx    context.Context
+}
+
+// WithContext returns a Traced of the same buffer whose spans are started
+// with ctx, so that they are part of the trace of the caller:
+//
+//	buf.WithContext(ctx).Append(msg)
+func (b *Traced[F]) WithContext(ctx context.Context) *Traced[F] {
+	return &Traced[F]{
+		Buffer: b.Buffer,
+		tracer: b.tracer,
+		ctx:    ctx,
+	}
+}
+
+func (b *Traced[F]) start(op string) Span {
+	return b.tracer.Start(b.ctx, op)
+}
+
+func (b *Traced[F]) overwrites() bool {
+	return overwrites(b.Buffer)
+}
+
+func (b *Traced[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *Traced[F]) AppendPos(item F) (Position, error) {
+	span := b.start("AppendPos")
+	pos, err := b.Buffer.AppendPos(item)
+	span.End(TraceEvent{Position: pos, Count: countIfOK(1, err), Err: err})
+	return pos, err
+}
+
+func (b *Traced[F]) Drop(drop Position) error {
+	span := b.start("Drop")
+	n := b.Len()
+	err := b.Buffer.Drop(drop)
+	span.End(TraceEvent{Position: drop, Count: n - b.Len(), Err: err})
+	return err
+}
+
+func (b *Traced[F]) Roll(drop Position, items ...F) error {
+	span := b.start("Roll")
+	next := b.NextPosition()
+	err := b.Buffer.Roll(drop, items...)
+	span.End(TraceEvent{Position: drop, Count: int(b.NextPosition() - next), Err: err})
+	return err
+}
+
+func (b *Traced[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *Traced[F]) ResetAt(start Position) {
+	span := b.start("ResetAt")
+	n := b.Len()
+	b.Buffer.ResetAt(start)
+	span.End(TraceEvent{Position: start, Count: n})
+}
+
+func (b *Traced[F]) Iterator(start Position) (*Iterator[F], error) {
+	span := b.start("Iterator")
+	iter, err := b.Buffer.Iterator(start)
+	n := 0
+	if err == nil {
+		n = iter.len()
+	}
+	span.End(TraceEvent{Position: start, Count: n, Err: err})
+	return iter, err
+}
+
+func (b *Traced[F]) ToSlice(start Position) ([]F, error) {
+	span := b.start("ToSlice")
+	items, err := b.Buffer.ToSlice(start)
+	span.End(TraceEvent{Position: start, Count: len(items), Err: err})
+	return items, err
+}
+
+func (b *Traced[F]) ToSliceRange(start, end Position) ([]F, error) {
+	span := b.start("ToSliceRange")
+	items, err := b.Buffer.ToSliceRange(start, end)
+	span.End(TraceEvent{Position: start, Count: len(items), Err: err})
+	return items, err
+}
+
+func (b *Traced[F]) ToSliceN(start Position, max int) ([]F, error) {
+	span := b.start("ToSliceN")
+	items, err := b.Buffer.ToSliceN(start, max)
+	span.End(TraceEvent{Position: start, Count: len(items), Err: err})
+	return items, err
+}
+
+func (b *Traced[F]) CopyTo(dst []F, start Position) (int, error) {
+	span := b.start("CopyTo")
+	n, err := b.Buffer.CopyTo(dst, start)
+	span.End(TraceEvent{Position: start, Count: n, Err: err})
+	return n, err
+}
+
+func (b *Traced[F]) Get(pos Position) (F, error) {
+	span := b.start("Get")
+	item, err := b.Buffer.Get(pos)
+	span.End(TraceEvent{Position: pos, Count: countIfOK(1, err), Err: err})
+	return item, err
+}
+
+func (b *Traced[F]) Clone() Buffer[F] {
+	return NewTraced[F](b.Buffer.Clone(), b.tracer).WithContext(b.ctx)
+}
+
+// countIfOK returns n, or 0 if err is not nil.
+func countIfOK(n int, err error) int {
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,86 @@
+package ringbuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tracedOp struct {
+	op string
+	ev TraceEvent
+}
+
+type recordingTracer struct {
+	ops  []tracedOp
+	ctxs []context.Context
+}
+
+func (t *recordingTracer) Start(ctx context.Context, op string) Span {
+	t.ctxs = append(t.ctxs, ctx)
+	return recordingSpan{t: t, op: op}
+}
+
+type recordingSpan struct {
+	t  *recordingTracer
+	op string
+}
+
+func (s recordingSpan) End(ev TraceEvent) {
+	s.t.ops = append(s.t.ops, tracedOp{op: s.op, ev: ev})
+}
+
+func TestTraced(t *testing.T) {
+	tracer := &recordingTracer{}
+	buf := NewTraced[int](NewRingBuf[int](2), tracer)
+	assert.NoError(t, buf.Append(0))
+	assert.NoError(t, buf.Append(1))
+	overflow := buf.Append(2)
+	assert.True(t, errors.Is(overflow, ErrBufferOverflow))
+	items, err := buf.ToSlice(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, items)
+	assert.NoError(t, buf.Drop(0))
+	_, err = buf.Get(0)
+	assert.NoError(t, err) // dropped but not overwritten
+	assert.NoError(t, buf.Roll(1, 2))
+	iter, err := buf.Iterator(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, iter.ToSlice())
+	buf.ResetAt(5)
+
+	assert.Equal(t, []tracedOp{
+		{op: "AppendPos", ev: TraceEvent{Position: 0, Count: 1}},
+		{op: "AppendPos", ev: TraceEvent{Position: 1, Count: 1}},
+		{op: "AppendPos", ev: TraceEvent{Position: 2, Err: overflow}},
+		{op: "ToSlice", ev: TraceEvent{Position: 0, Count: 2}},
+		{op: "Drop", ev: TraceEvent{Position: 0, Count: 1}},
+		{op: "Get", ev: TraceEvent{Position: 0, Count: 1}},
+		{op: "Roll", ev: TraceEvent{Position: 1, Count: 1}},
+		{op: "Iterator", ev: TraceEvent{Position: 2, Count: 1}},
+		{op: "ResetAt", ev: TraceEvent{Position: 5, Count: 1}},
+	}, tracer.ops)
+
+	clone := buf.Clone()
+	_, _ = clone.ToSliceN(5, 1)
+	assert.Equal(t, "ToSliceN", tracer.ops[len(tracer.ops)-1].op)
+}
+
+type traceKey struct{}
+
+func TestTracedWithContext(t *testing.T) {
+	tracer := &recordingTracer{}
+	buf := NewTraced[int](NewRingBuf[int](2), tracer)
+	assert.NoError(t, buf.Append(0))
+	ctx := context.WithValue(context.Background(), traceKey{}, "parent")
+	assert.NoError(t, buf.WithContext(ctx).Append(1))
+	_, err := buf.WithContext(ctx).Clone().ToSlice(0)
+	assert.NoError(t, err)
+
+	assert.Nil(t, tracer.ctxs[0].Value(traceKey{}))
+	assert.Equal(t, "parent", tracer.ctxs[1].Value(traceKey{}))
+	assert.Equal(t, "parent", tracer.ctxs[2].Value(traceKey{}))
+	assert.Equal(t, 2, buf.Len())
+}
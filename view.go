@@ -0,0 +1,138 @@
+package ringbuf
+
+// View returns a Reader of the items of r in [start, end), which must be
+// within the bounds of r. The bounds and positions of the view are those of r
+// limited to the window, so that it shrinks as r drops its items but never
+// grows past end, and code given the view can only read that window. Like
+// ReadOnly, it cannot be converted back to r.
+func View[F any](r Reader[F], start, end Position) (Reader[F], error) {
+	first, next := r.Bounds()
+	if start-first < 0 || next-start < 0 {
+		return nil, errOutOfRange(start, first, next)
+	}
+	if end-start < 0 || next-end < 0 {
+		return nil, errOutOfRange(end, start, next)
+	}
+	return view[F]{r: r, start: start, end: end}, nil
+}
+
+type view[F any] struct {
+	r          Reader[F]
+	start, end Position
+}
+
+// clamp returns pos limited to [v.start, v.end].
+func (v view[F]) clamp(pos Position) Position {
+	if pos-v.start < 0 {
+		return v.start
+	}
+	if v.end-pos < 0 {
+		return v.end
+	}
+	return pos
+}
+
+// check returns the bounds of v, or ErrOutOfRange if start is outside them.
+func (v view[F]) check(start Position) (Position, Position, error) {
+	first, next := v.Bounds()
+	if start-first < 0 || next-start < 0 {
+		return first, next, errOutOfRange(start, first, next)
+	}
+	return first, next, nil
+}
+
+func (v view[F]) Iterator(start Position) (*Iterator[F], error) {
+	items, err := v.ToSlice(start)
+	if err != nil {
+		return nil, err
+	}
+	return NewIteratorAt[F](start, items), nil
+}
+
+func (v view[F]) ToSlice(start Position) ([]F, error) {
+	_, next, err := v.check(start)
+	if err != nil {
+		return nil, err
+	}
+	return v.r.ToSliceRange(start, next)
+}
+
+func (v view[F]) Bounds() (Position, Position) {
+	first, next := v.r.Bounds()
+	return v.clamp(first), v.clamp(next)
+}
+
+func (v view[F]) Len() int {
+	return int(v.NextPosition() - v.FirstPosition())
+}
+
+// Cap returns the size of the window.
+func (v view[F]) Cap() int {
+	return int(v.end - v.start)
+}
+
+func (v view[F]) Free() int {
+	return v.Cap() - v.Len()
+}
+
+func (v view[F]) IsEmpty() bool {
+	return v.Len() <= 0
+}
+
+func (v view[F]) IsFull() bool {
+	return v.Free() <= 0
+}
+
+func (v view[F]) Get(pos Position) (F, error) {
+	if first, next := v.Bounds(); pos-first < 0 || next-pos <= 0 {
+		var zero F
+		return zero, errOutOfRange(pos, first, next)
+	}
+	return v.r.Get(pos)
+}
+
+func (v view[F]) ToSliceRange(start, end Position) ([]F, error) {
+	_, next, err := v.check(start)
+	if err != nil {
+		return nil, err
+	}
+	if end-start < 0 || next-end < 0 {
+		return nil, errOutOfRange(end, start, next)
+	}
+	return v.r.ToSliceRange(start, end)
+}
+
+func (v view[F]) CopyTo(dst []F, start Position) (int, error) {
+	_, next, err := v.check(start)
+	if err != nil {
+		return 0, err
+	}
+	if n := int(next - start); n < len(dst) {
+		dst = dst[:n]
+	}
+	return v.r.CopyTo(dst, start)
+}
+
+func (v view[F]) FirstPosition() Position {
+	return v.clamp(v.r.FirstPosition())
+}
+
+func (v view[F]) NextPosition() Position {
+	return v.clamp(v.r.NextPosition())
+}
+
+func (v view[F]) ToSliceN(start Position, max int) ([]F, error) {
+	_, next, err := v.check(start)
+	if err != nil {
+		return nil, err
+	}
+	if n := int(next - start); n < max {
+		max = n
+	}
+	return v.r.ToSliceN(start, max)
+}
+
+// Stats returns the statistics of the whole underlying buffer.
+func (v view[F]) Stats() Stats {
+	return v.r.Stats()
+}
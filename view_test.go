@@ -0,0 +1,88 @@
+package ringbuf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestView(t *testing.T) {
+	buf := NewRingBuf[int](5)
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, buf.Append(i))
+	}
+	v, err := View[int](buf, 1, 4)
+	assert.NoError(t, err)
+	_, ok := v.(Dropper)
+	assert.False(t, ok)
+	first, next := v.Bounds()
+	assert.Equal(t, Position(1), first)
+	assert.Equal(t, Position(4), next)
+	assert.Equal(t, 3, v.Len())
+	assert.Equal(t, 3, v.Cap())
+	assert.Equal(t, 0, v.Free())
+
+	items, err := v.ToSlice(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, items)
+	iter, err := v.Iterator(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, iter.ToSlice())
+	items, err = v.ToSliceN(2, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, items)
+	items, err = v.ToSliceRange(1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, items)
+	dst := make([]int, 5)
+	n, err := v.CopyTo(dst, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 3, dst[0])
+	item, err := v.Get(3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, item)
+
+	_, err = v.Get(4)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = v.Get(0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = v.ToSlice(0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = v.ToSliceRange(1, 5)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+
+	assert.NoError(t, buf.Drop(1))
+	assert.Equal(t, Position(2), v.FirstPosition())
+	assert.Equal(t, 2, v.Len())
+	assert.NoError(t, buf.Drop(4))
+	assert.Equal(t, 0, v.Len())
+	assert.Equal(t, Position(4), v.FirstPosition())
+	assert.Equal(t, Position(4), v.NextPosition())
+	items, err = v.ToSlice(2) // dropped but not overwritten, as in buf
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, items)
+
+	_, err = View[int](buf, 4, 6)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = View[int](buf, -1, 2)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = View[int](buf, 3, 2)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+	_, err = View[int](buf, 5, 5)
+	assert.NoError(t, err)
+
+	spsc := NewSPSCBuf[int](3)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, spsc.Append(i))
+	}
+	v, err = View[int](spsc, 0, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, spsc.Drop(0))
+	first, next = v.Bounds()
+	assert.Equal(t, Position(1), first)
+	assert.Equal(t, Position(2), next)
+	_, err = v.ToSlice(0)
+	assert.True(t, errors.Is(err, ErrOutOfRange))
+}
@@ -0,0 +1,158 @@
+package ringbuf
+
+import "fmt"
+
+// NewWeightedBuf returns a WeightedBuf decorating buf, holding items up to a
+// total cost of budget. If evict is true, an append that exceeds the budget
+// drops the oldest items to make room; otherwise it fails with
+// ErrBufferOverflow.
+func NewWeightedBuf[F any](buf Buffer[F], budget int, cost func(item F) int, evict bool) *WeightedBuf[F] {
+	b := &WeightedBuf[F]{
+		Buffer: buf,
+		budget: budget,
+		cost:   cost,
+		evict:  evict,
+	}
+	b.load()
+	return b
+}
+
+// WeightedBuf is a Buffer decorator limiting the total cost of the retained
+// items, e.g. their size in bytes, in addition to the capacity of buf.
+type WeightedBuf[F any] struct {
+	Buffer[F]
+	budget int
+	cost   func(item F) int
+	evict  bool
+	start  Position // position of costs[0]
+	costs  []int
+	total  int
+}
+
+func (b *WeightedBuf[F]) Append(item F) error {
+	_, err := b.AppendPos(item)
+	return err
+}
+
+func (b *WeightedBuf[F]) AppendPos(item F) (Position, error) {
+	c := b.cost(item)
+	if b.budget < b.total+c {
+		if !b.evict || b.budget < c {
+			return b.NextPosition(), fmt.Errorf("%w: cost %v over the budget %v of which %v is used", ErrBufferOverflow, c, b.budget, b.total)
+		}
+		if err := b.Buffer.Drop(b.start + Position(b.excess(c)) - 1); err != nil {
+			return b.NextPosition(), err
+		}
+		b.trim()
+	}
+	pos, err := b.Buffer.AppendPos(item)
+	if err != nil {
+		return pos, err
+	}
+	b.costs = append(b.costs, c)
+	b.total += c
+	b.trim() // an overwriting append may have dropped items
+	return pos, nil
+}
+
+// excess returns the number of the oldest items to drop so that c fits.
+func (b *WeightedBuf[F]) excess(c int) int {
+	total := b.total
+	n := 0
+	for b.budget < total+c {
+		total -= b.costs[n]
+		n++
+	}
+	return n
+}
+
+// Roll is like the Roll of other buffers, also checking that the items stay
+// within the budget once drop is applied unless the buffer evicts.
+func (b *WeightedBuf[F]) Roll(drop Position, items ...F) error {
+	if err := checkRoll[F](b, drop, len(items)); err != nil {
+		return err
+	}
+	total := b.total
+	if n := int(drop - b.start + 1); 0 < n {
+		for _, c := range b.costs[:n] {
+			total -= c
+		}
+	}
+	for _, item := range items {
+		c := b.cost(item)
+		if b.budget < c || !b.evict && b.budget < total+c {
+			return fmt.Errorf("%w: cost %v over the budget %v of which %v is used", ErrBufferOverflow, c, b.budget, total)
+		}
+		total += c
+	}
+	return applyRoll[F](b, drop, items)
+}
+
+func (b *WeightedBuf[F]) overwrites() bool {
+	return overwrites(b.Buffer)
+}
+
+func (b *WeightedBuf[F]) Drop(drop Position) error {
+	if err := b.Buffer.Drop(drop); err != nil {
+		return err
+	}
+	b.trim()
+	return nil
+}
+
+func (b *WeightedBuf[F]) Reset() {
+	b.ResetAt(0)
+}
+
+func (b *WeightedBuf[F]) ResetAt(start Position) {
+	b.Buffer.ResetAt(start)
+	b.load()
+}
+
+func (b *WeightedBuf[F]) Clone() Buffer[F] {
+	return NewWeightedBuf[F](b.Buffer.Clone(), b.budget, b.cost, b.evict)
+}
+
+// Weight returns the total cost of the retained items.
+func (b *WeightedBuf[F]) Weight() int {
+	return b.total
+}
+
+// Budget returns the maximum total cost.
+func (b *WeightedBuf[F]) Budget() int {
+	return b.budget
+}
+
+// load computes the costs of the retained items from scratch.
+func (b *WeightedBuf[F]) load() {
+	b.start = b.FirstPosition()
+	b.costs = nil
+	b.total = 0
+	items, err := b.ToSlice(b.start)
+	if err != nil {
+		return
+	}
+	for _, item := range items {
+		c := b.cost(item)
+		b.costs = append(b.costs, c)
+		b.total += c
+	}
+}
+
+// trim removes the costs of the items dropped from the buffer.
+func (b *WeightedBuf[F]) trim() {
+	first := b.FirstPosition()
+	n := int(first - b.start)
+	if n < 0 { // Drop moved back to items not weighed
+		b.load()
+		return
+	}
+	if len(b.costs) < n {
+		n = len(b.costs)
+	}
+	for _, c := range b.costs[:n] {
+		b.total -= c
+	}
+	b.costs = b.costs[n:]
+	b.start = first
+}
@@ -0,0 +1,73 @@
+package ringbuf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedBuf(t *testing.T) {
+	size := func(item []byte) int { return len(item) }
+	buf := NewWeightedBuf[[]byte](NewRingBuf[[]byte](10), 10, size, false)
+	assert.NoError(t, buf.Append(make([]byte, 4)))
+	assert.NoError(t, buf.Append(make([]byte, 6)))
+	assert.Equal(t, 10, buf.Weight())
+	pos, err := buf.AppendPos(make([]byte, 1))
+	assert.True(t, errors.Is(err, ErrBufferOverflow))
+	assert.Equal(t, Position(2), pos)
+	assert.Equal(t, 2, buf.Len())
+
+	assert.NoError(t, buf.Drop(0))
+	assert.Equal(t, 6, buf.Weight())
+	assert.NoError(t, buf.Append(make([]byte, 4)))
+	assert.Equal(t, 10, buf.Weight())
+
+	clone := buf.Clone().(*WeightedBuf[[]byte])
+	buf.ResetAt(5)
+	assert.Equal(t, 0, buf.Weight())
+	assert.Equal(t, 10, clone.Weight())
+	assert.Equal(t, 10, clone.Budget())
+}
+
+func TestWeightedBufRoll(t *testing.T) {
+	cost := func(item int) int { return item }
+	buf := NewWeightedBuf[int](NewRingBuf[int](10), 10, cost, false)
+	assert.NoError(t, buf.Roll(-1, 3, 3, 3))
+	assert.True(t, errors.Is(buf.Roll(0, 5), ErrBufferOverflow))
+	assert.Equal(t, 9, buf.Weight())
+	assert.Equal(t, Position(0), buf.FirstPosition())
+	assert.NoError(t, buf.Roll(1, 4))
+	assert.Equal(t, 7, buf.Weight())
+}
+
+func TestWeightedBufEvict(t *testing.T) {
+	cost := func(item int) int { return item }
+	buf := NewWeightedBuf[int](NewRingBuf[int](10), 10, cost, true)
+	for _, item := range []int{3, 3, 3} {
+		assert.NoError(t, buf.Append(item))
+	}
+	assert.NoError(t, buf.Append(5))
+	items, err := buf.ToSlice(buf.FirstPosition())
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 5}, items)
+	assert.Equal(t, 8, buf.Weight())
+	assert.Equal(t, Position(2), buf.FirstPosition())
+
+	assert.True(t, errors.Is(buf.Append(11), ErrBufferOverflow))
+	assert.Equal(t, 8, buf.Weight())
+	assert.NoError(t, buf.Append(10))
+	assert.Equal(t, 10, buf.Weight())
+	assert.Equal(t, 1, buf.Len())
+}
+
+func TestWeightedBufOverwrite(t *testing.T) {
+	cost := func(item int) int { return item }
+	buf := NewWeightedBuf[int](NewRingBuf[int](2, WithOverwrite[int]()), 10, cost, false)
+	for _, item := range []int{1, 2, 3} {
+		assert.NoError(t, buf.Append(item))
+	}
+	assert.Equal(t, 5, buf.Weight())
+	assert.NoError(t, buf.Drop(1))
+	assert.Equal(t, 3, buf.Weight())
+}